@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mitsimi/aocli/internal/aoc"
+	"github.com/mitsimi/aocli/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local cache of puzzle data",
+	Long: `Manage the local cache of puzzle data.
+Inputs are only downloaded once per account. Descriptions are downloaded again while part two may unlock, but at most every few minutes.
+This keeps the number of requests to adventofcode.com as low as possible.`,
+	// the cache can be managed without a session token
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return checkConfig(cmd)
+	},
+}
+
+var cacheLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the cached puzzle data",
+	Args:  cobra.NoArgs,
+	RunE:  executeCacheLs,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove cached puzzle data",
+	Long: `Remove cached puzzle data.
+Without flags the whole cache is removed, with the year and day flags only the data of the matching puzzles.`,
+	Args: cobra.NoArgs,
+	RunE: executeCacheClear,
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove cached puzzle data which would be downloaded again anyway",
+	Long: `Remove cached puzzle data which would be downloaded again anyway.
+These are the descriptions of puzzles which are not completed yet, they are only used for a few minutes, and, if specified, all entries older than the given duration.`,
+	Args: cobra.NoArgs,
+	RunE: executeCachePrune,
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheLsCmd, cacheClearCmd, cachePruneCmd)
+
+	cacheClearCmd.Flags().IntP("year", "y", 0, "only remove the data of puzzles from this year")
+	cacheClearCmd.Flags().IntP("day", "d", 0, "only remove the data of puzzles from this day")
+
+	cachePruneCmd.Flags().Duration("older-than", 0, "also remove all entries older than this duration (e.g. 720h)")
+}
+
+// openCache returns the cache in the default location, every profile has its own
+func openCache() (*cache.Cache, error) {
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find the cache directory: %v", err)
+	}
+	if activeProfile != "" {
+		dir = filepath.Join(dir, "profiles", activeProfile)
+	}
+	return cache.New(dir), nil
+}
+
+func executeCacheLs(cmd *cobra.Command, args []string) error {
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	entries, err := c.List()
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		cmd.Println("The cache is empty.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ACCOUNT\tYEAR\tDAY\tRESOURCE\tSIZE\tCACHED")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%d\t%s\n", e.Account, e.Year, e.Day, e.Resource, e.Size, e.ModTime.Format(time.DateTime))
+	}
+	return w.Flush()
+}
+
+func executeCacheClear(cmd *cobra.Command, args []string) error {
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	year, _ := cmd.Flags().GetInt("year")
+	if year != 0 && year < 100 {
+		year += 2000
+	}
+	day, _ := cmd.Flags().GetInt("day")
+
+	n, err := c.Remove(func(e cache.Entry) bool {
+		return (year == 0 || e.Year == year) && (day == 0 || e.Day == day)
+	})
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("Removed %d cached entries.\n", n)
+	return nil
+}
+
+func executeCachePrune(cmd *cobra.Command, args []string) error {
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	olderThan, _ := cmd.Flags().GetDuration("older-than")
+
+	n, err := c.Remove(func(e cache.Entry) bool {
+		if olderThan > 0 && time.Since(e.ModTime) > olderThan {
+			return true
+		}
+		if e.Resource != aoc.ResourcePage {
+			return false
+		}
+		page, _, ok := c.Get(e.Key)
+		return !ok || !aoc.IsPageComplete(page)
+	})
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("Removed %d cached entries.\n", n)
+	return nil
+}
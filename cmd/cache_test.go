@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitsimi/aocli/internal/aoctest"
+)
+
+func TestCachePrune(t *testing.T) {
+	s := newTestServer(t)
+	dir := t.TempDir()
+	runAocli(t, dir, "download", "-y", "2024", "-d", "1")
+	if out := runAocli(t, dir, "cache", "ls"); strings.Count(out, "2024") != 2 {
+		t.Fatalf("the page and the input aren't cached:\n%s", out)
+	}
+
+	// the page of the unsolved puzzle would be downloaded again anyway
+	if out := runAocli(t, dir, "cache", "prune"); !strings.Contains(out, "Removed 1 cached entries.") {
+		t.Errorf("prune didn't remove the page:\n%s", out)
+	}
+	out := runAocli(t, dir, "cache", "ls")
+	if strings.Contains(out, "page") || !strings.Contains(out, "input") {
+		t.Errorf("the input must be kept:\n%s", out)
+	}
+
+	// a complete page is kept
+	s.Puzzle(2024, 1).Solved = 2
+	runAocli(t, dir, "download", "-y", "2024", "-d", "1", "-D", "--update")
+	if out := runAocli(t, dir, "cache", "prune"); !strings.Contains(out, "Removed 0 cached entries.") {
+		t.Errorf("prune removed the complete page:\n%s", out)
+	}
+
+	if out := runAocli(t, dir, "cache", "prune", "--older-than", "1ns"); !strings.Contains(out, "Removed 2 cached entries.") {
+		t.Errorf("prune didn't remove the old entries:\n%s", out)
+	}
+	if out := runAocli(t, dir, "cache", "ls"); !strings.Contains(out, "The cache is empty.") {
+		t.Errorf("the cache isn't empty:\n%s", out)
+	}
+}
+
+func TestCacheClear(t *testing.T) {
+	s := newTestServer(t)
+	s.AddPuzzle(aoctest.NewPuzzle(2024, 2))
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{".aocli.toml": "year = 2024\n"})
+	runAocli(t, dir, "download", "-d", "1", "-I")
+	runAocli(t, dir, "download", "-d", "2", "-I")
+
+	if out := runAocli(t, dir, "cache", "clear", "-d", "2"); !strings.Contains(out, "Removed 1 cached entries.") {
+		t.Errorf("clear didn't remove day 2:\n%s", out)
+	}
+	if out := runAocli(t, dir, "cache", "clear"); !strings.Contains(out, "Removed 1 cached entries.") {
+		t.Errorf("clear didn't remove the rest:\n%s", out)
+	}
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitsimi/aocli/internal/aoctest"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const testSession = "test-session"
+
+// newTestServer starts a fake site with day 1 of 2024 and points the config of the commands to it.
+// The home, config and cache folders are temporary, so the tests never touch the ones of the user.
+func newTestServer(t *testing.T) *aoctest.Server {
+	t.Helper()
+	s := aoctest.NewServer(testSession)
+	t.Cleanup(s.Close)
+	s.AddPuzzle(aoctest.NewPuzzle(2024, 1))
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	t.Setenv("AOC_SESSION", testSession)
+	t.Setenv("AOCLI_BASE_URL", s.URL)
+	t.Setenv("AOCLI_RATE_LIMIT", "0s")
+	return s
+}
+
+// runAocli runs the command line in the folder and returns everything it printed
+func runAocli(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := runAocliErr(t, dir, args...)
+	if err != nil {
+		t.Fatalf("aocli %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return out
+}
+
+// runAocliErr runs the command line in the folder and returns everything it printed and the error of the command
+func runAocliErr(t *testing.T, dir string, args ...string) (string, error) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	// the commands and their state are package variables, nothing of this run may leak into the next one
+	resetCommands()
+	t.Cleanup(resetCommands)
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	rootCmd.SetArgs(args)
+	err = rootCmd.ExecuteContext(context.Background())
+	return out.String(), err
+}
+
+// resetCommands resets the flags and the state cached by a run, like the looked up session
+func resetCommands() {
+	resetFlags(rootCmd)
+	sessionLookup.done = false
+	plaintextSessions = nil
+	conf = nil
+	client = nil
+	activeProfile = ""
+	configErr = nil
+	configWarnings = nil
+	configLayers = nil
+}
+
+// resetFlags sets the flags of the command and its subcommands back to their defaults
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		f.Value.Set(f.DefValue)
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, c := range cmd.Commands() {
+		resetFlags(c)
+	}
+}
+
+// readFile returns the content of the file or fails the test
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
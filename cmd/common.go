@@ -1,33 +1,12 @@
 package cmd
 
 import (
-	"fmt"
-	"os"
-	"path/filepath"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/mitsimi/aocli/internal/aoc"
 	"github.com/spf13/cobra"
 )
 
-// getDayFromCurrentDir returns the day number from the current working directory
-func getDayFromCurrentDir() (string, error) {
-	// Get working dir, which equals to the location from where the command is issued
-	wd, err := os.Getwd()
-	if err != nil {
-		return "", err
-	}
-
-	base := filepath.Base(wd)
-	base = strings.ToLower(base)
-	if strings.HasPrefix(base, "day") && len(base) == 5 {
-		return base[3:], nil // Extract day number
-	}
-	return "", fmt.Errorf("not in a day folder")
-}
-
 // getDefaultYear returns the latest year where a event is available
 func getDefaultYear() int {
 	yearVal := time.Now().Year()
@@ -40,11 +19,8 @@ func getDefaultYear() int {
 
 // getDefaultDay returns the latest day where an event is available
 func getDefaultDay() int {
-	if d, err := getDayFromCurrentDir(); err == nil {
-		n, err := strconv.Atoi(d)
-		if err == nil {
-			return n
-		}
+	if loc, err := locatePuzzle(); err == nil && loc.puzzle.Day != 0 {
+		return loc.puzzle.Day
 	}
 
 	year := getDefaultYear()
@@ -67,7 +43,8 @@ func getDay(cmd *cobra.Command) int {
 	return getDefaultDay()
 }
 
-// getYear returns the year from the flag, config or default.
+// getYear returns the year from the flag, the path of the current folder, config or default.
+// If the layout has no year, a parent folder named after the year is used instead of the path.
 // The default is the current or last event year
 func getYear(cmd *cobra.Command) int {
 	// function to use to convert the year shorthand ex. 19 to 2019
@@ -83,6 +60,16 @@ func getYear(cmd *cobra.Command) int {
 		return conv(year)
 	}
 
+	if loc, err := locatePuzzle(); err == nil && loc.puzzle.Year != 0 {
+		return loc.puzzle.Year
+	}
+
+	if l, err := currentLayout(); err == nil && !l.UsesYear() {
+		if year, ok := findYearDir(); ok {
+			return year
+		}
+	}
+
 	if year := conf.Year; year != 0 {
 		return conv(year)
 	}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitsimi/aocli/internal/aoctest"
+)
+
+func TestYearFromFolder(t *testing.T) {
+	s := newTestServer(t)
+	s.AddPuzzle(aoctest.NewPuzzle(2023, 1))
+
+	tests := []struct {
+		name    string
+		project string
+		config  string
+		wd      string
+		want    string
+	}{
+		// the single-year layout has no year, a project in a year folder still gets it
+		{"single-year project in a year folder", "2023", "", "2023/day01", "for 2023/1"},
+		{"root of the project", "2023", "", "2023", "for 2023/1"},
+		{"config year is overruled by the folder", "2023", "year = 2024\n", "2023/day01", "for 2023/1"},
+		{"no year folder", "aoc", "year = 2024\n", "aoc/day01", "for 2024/1"},
+		{"year folder above the project", "2023/aoc", "year = 2024\n", "2023/aoc/day01", "for 2024/1"},
+		// with the year in the layout only the path of the layout counts
+		{"multi-year layout", "2023", "year = 2024\nstructure = \"multi-year\"\n", "2023", "for 2024/1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			wd := filepath.Join(tmp, filepath.FromSlash(tt.wd))
+			if err := os.MkdirAll(wd, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(tmp, filepath.FromSlash(tt.project), ".aocli.toml"), []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			out := runAocli(t, wd, "submit", "-d", "1", "15")
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("submitted %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
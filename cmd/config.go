@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mitsimi/aocli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and change the configuration",
+	Long: `Inspect and change the configuration.
+The global config files, the one of the project, the AOCLI_* environment variables and the file given with --config are merged in this order, the last value wins.
+The keys of a profile are written as profiles.NAME.KEY.`,
+	// a broken config file has to be fixable with "config set"
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the effective configuration",
+	Args:  cobra.NoArgs,
+	RunE:  executeConfigList,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get KEY",
+	Short: "Print the effective value of a key",
+	Args:  cobra.ExactArgs(1),
+	RunE:  executeConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set KEY VALUE",
+	Short: "Set the value of a key in a config file",
+	Long: `Set the value of a key in a config file, an empty value removes the key.
+Without flags the value is written into the file given with --config, otherwise into the project config if there is one and else into the global config.
+The file is written in its own format.`,
+	Args: cobra.ExactArgs(2),
+	RunE: executeConfigSet,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [FILE]...",
+	Short: "Check the config files for invalid values",
+	Long: `Check the config files for invalid values.
+Without arguments all config files which are in use and the AOCLI_* environment variables are checked.
+The problems are reported with their line and column in TOML and YAML files.`,
+	RunE: executeConfigValidate,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configListCmd, configGetCmd, configSetCmd, configValidateCmd)
+
+	configListCmd.Flags().Bool("show-origin", false, "show the file every value comes from")
+
+	configSetCmd.Flags().Bool("global", false, "write into the global config (e.g. ~/.config/aocli/config.toml)")
+	configSetCmd.Flags().Bool("project", false, "write into the config of the project, it is created in the current folder if there is none")
+	configSetCmd.MarkFlagsMutuallyExclusive("global", "project")
+}
+
+// secretKeys are the keys whose values aren't listed, so the output can be shared
+var secretKeys = []string{"session"}
+
+func executeConfigList(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	if err := checkConfig(cmd); err != nil {
+		return err
+	}
+
+	showOrigin, _ := cmd.Flags().GetBool("show-origin")
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	for _, key := range conf.SetKeys() {
+		value, _, _ := conf.Get(key)
+		if slices.Contains(secretKeys, key[strings.LastIndex(key, ".")+1:]) {
+			value = "(hidden)"
+		}
+
+		if showOrigin {
+			fmt.Fprintf(w, "%s\t%s=%s\n", configKeyOrigin(key), key, value)
+		} else {
+			fmt.Fprintf(w, "%s=%s\n", key, value)
+		}
+	}
+	return w.Flush()
+}
+
+func executeConfigGet(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	if err := checkConfig(cmd); err != nil {
+		return err
+	}
+
+	value, ok, err := conf.Get(args[0])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%s is not set", args[0])
+	}
+
+	cmd.Println(value)
+	return nil
+}
+
+func executeConfigSet(cmd *cobra.Command, args []string) error {
+	key, value := args[0], args[1]
+
+	// check the key before a file is touched
+	if _, _, err := (&config.Config{}).Get(key); err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	path, err := configSetPath(cmd)
+	if err != nil {
+		return err
+	}
+
+	c, err := readConfigFile(path)
+	if err != nil {
+		return fmt.Errorf("Failed to parse the config file %s: %v", path, err)
+	}
+	if err := c.Set(key, value); err != nil {
+		return err
+	}
+	// only the new value is checked, so a file with other problems can still be fixed key by key
+	var verr *config.ValidationError
+	if errors.As(c.Validate(), &verr) {
+		for _, p := range verr.Problems {
+			if p.Key == key {
+				return fmt.Errorf("invalid value for %s: %s", key, p.Message)
+			}
+		}
+	}
+	if err := c.Update(path); err != nil {
+		return fmt.Errorf("Failed to write %s: %v", path, err)
+	}
+
+	if value == "" {
+		cmd.Printf("Removed %s from %s\n", key, path)
+	} else {
+		cmd.Printf("Set %s in %s\n", key, path)
+	}
+	return nil
+}
+
+func executeConfigValidate(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	files := args
+	if len(files) == 0 {
+		files = globalConfigFiles()
+		if path, err := findConfigInProject(); err == nil {
+			files = append(files, path)
+		}
+		if cfgFlag != "" {
+			files = append(files, cfgFlag)
+		}
+	}
+
+	checked, invalid := len(files), 0
+	for _, path := range files {
+		c, err := config.Parse(path)
+		if err == nil {
+			err = config.ValidateFile(path, c)
+		}
+		if err != nil {
+			invalid++
+			cmd.PrintErrln(err)
+			continue
+		}
+		cmd.Println(path + ": ok")
+	}
+
+	if len(args) == 0 {
+		checked++
+		if _, err := envConfig(); err != nil {
+			invalid++
+			cmd.PrintErrln(err)
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d configs are invalid", invalid, checked)
+	}
+	return nil
+}
+
+// configSetPath returns the config file which is changed by "config set"
+func configSetPath(cmd *cobra.Command) (string, error) {
+	global, _ := cmd.Flags().GetBool("global")
+	project, _ := cmd.Flags().GetBool("project")
+
+	switch {
+	case global:
+		return globalConfigPath()
+	case project:
+		if path, err := findConfigInProject(); err == nil {
+			return path, nil
+		}
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(wd, projectConfigNames[0]), nil
+	case cfgFlag != "":
+		return cfgFlag, nil
+	}
+
+	if path, err := findConfigInProject(); err == nil {
+		return path, nil
+	}
+	return globalConfigPath()
+}
+
+// configKeyOrigin returns the file the effective value of the key comes from.
+// The top level keys which are replaced by the active profile come from the profile.
+func configKeyOrigin(key string) string {
+	if activeProfile != "" && slices.Contains(config.ProfileKeys(), key) {
+		if origin := lastLayerWith(profilesKey(activeProfile, key)); origin != "" {
+			return origin + " (profile " + activeProfile + ")"
+		}
+	}
+
+	if origin := lastLayerWith(key); origin != "" {
+		return origin
+	}
+	return "default"
+}
+
+// lastLayerWith returns the origin of the last merged layer which sets the key
+func lastLayerWith(key string) string {
+	for i := len(configLayers) - 1; i >= 0; i-- {
+		layer := configLayers[i]
+		if _, ok, _ := layer.conf.Get(key); !ok {
+			continue
+		}
+		if layer.env {
+			return "env:" + config.EnvName(key)
+		}
+		return "file:" + layer.path
+	}
+	return ""
+}
+
+func profilesKey(name, key string) string {
+	return "profiles." + name + "." + key
+}
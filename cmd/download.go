@@ -4,14 +4,15 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
-	"strconv"
 
 	"github.com/mitsimi/aocli/internal/aoc"
+	"github.com/mitsimi/aocli/internal/layout"
+	"github.com/mitsimi/aocli/internal/template"
 	"github.com/spf13/cobra"
 )
 
@@ -20,17 +21,23 @@ var downloadCmd = &cobra.Command{
 	Use:   "download",
 	Short: "Download the puzzle description, examples and inputs",
 	Long: `Download the puzzle description, examples and inputs.
-The files will be saved in the current folder or in the folder specified by the output flag.`,
+The files will be saved at the place of the puzzle in the layout of the workspace, in the current folder outside of a workspace or in the folder specified by the output flag.
+Existing files are kept unless --update or --force is given.`,
 	Args: cobra.NoArgs,
 	RunE: executeDownload,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkConfig(cmd); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+
 		// we download the input by default and if specified, because the input is generated per account we need a session token for it
-		if (!contentFlagsChanged(cmd) || cmd.Flag("input").Changed) && getSessionToken() == "" {
+		if (!contentFlagsChanged(cmd) || cmd.Flag("input").Changed) && getSessionToken() == "" && replayFlag == "" {
 			cmd.SilenceUsage = true
 			return errors.New("a session token is required to download the input")
 		}
 
-		client = aoc.NewClient(getSessionToken())
+		client = newClient(getSessionToken())
 		return nil
 	},
 }
@@ -45,7 +52,8 @@ func init() {
 	downloadCmd.Flags().BoolP("examples", "E", false, "download the examples")
 	downloadCmd.Flags().BoolP("input", "I", false, "download the input")
 
-	downloadCmd.Flags().StringP("output", "o", "", "output folder (default is the current folder)")
+	downloadCmd.Flags().StringP("output", "o", "", "output folder (default is the folder of the puzzle in the layout or the current folder)")
+	addOverwriteFlags(downloadCmd)
 }
 
 func executeDownload(cmd *cobra.Command, args []string) error {
@@ -53,9 +61,6 @@ func executeDownload(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	if dir == "" {
-		dir = "."
-	}
 
 	if !contentFlagsChanged(cmd) {
 		cmd.Flag("description").Value.Set("true")
@@ -70,92 +75,220 @@ func executeDownload(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("The given day is not unlocked.")
 	}
 
-	currentDir, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("Failed to get current directory: %v", err)
+	// the arguments are fine, errors from here on are about the site or the session
+	cmd.SilenceUsage = true
+
+	description, _ := cmd.Flags().GetBool("description")
+	examples, _ := cmd.Flags().GetBool("examples")
+
+	// the description, the examples and the title for the path are on the same page, so it only gets requested once
+	var page *aoc.PuzzlePage
+	getPage := func() (*aoc.PuzzlePage, error) {
+		if page != nil {
+			return page, nil
+		}
+		cmd.Println("Downloading puzzle page...")
+		var err error
+		page, err = client.GetPuzzlePageContext(cmd.Context(), year, day)
+		return page, err
 	}
 
-	yearReg := regexp.MustCompile(`\d{4}`)
-	if yearReg.MatchString(filepath.Base(currentDir)) {
-		year, _ = strconv.Atoi(yearReg.FindString(filepath.Base(currentDir)))
-	} else if yearReg.MatchString(filepath.Dir(currentDir)) {
-		year, _ = strconv.Atoi(yearReg.FindString(filepath.Dir(currentDir)))
+	w := &puzzleWriter{mode: getOverwriteMode(cmd)}
+	// --update and --force ask for the page again, part two may have been unlocked on the site since it was cached
+	if w.mode != keepFiles {
+		client.InvalidatePuzzle(year, day)
 	}
 
-	if ok, _ := cmd.Flags().GetBool("description"); ok {
-		cmd.Println("Downloading description...")
-		err = downloadDescription(year, day, dir)
-		if err != nil {
+	files := filesInDir(dir)
+	if dir == "" {
+		if files, err = downloadFiles(year, day, getPage); err != nil {
 			return err
 		}
 	}
 
-	if ok, _ := cmd.Flags().GetBool("examples"); ok {
-		cmd.Println("Downloading examples...")
-		err = downloadExample(year, day, dir)
-		if err != nil {
+	if description {
+		if err := saveDescription(w, getPage, files.description); err != nil {
+			return err
+		}
+	}
+
+	if examples {
+		if err := saveExample(w, getPage, files.example); err != nil {
 			return err
 		}
 	}
 
 	if ok, _ := cmd.Flags().GetBool("input"); ok {
-		cmd.Println("Downloading input...")
-		err = downloadInput(year, day, dir)
+		err = downloadInput(cmd.Context(), w, year, day, files.input)
 		if err != nil {
 			return err
 		}
 	}
 
+	w.printSummary(cmd)
 	return nil
 }
 
-func downloadDescription(year, day int, dir string) error {
-	content, err := client.GetDescription(year, day)
+// downloadFiles returns the files of the puzzle at its place in the layout and creates its folder.
+// Outside of a workspace the files are saved in the current folder.
+func downloadFiles(year, day int, getPage func() (*aoc.PuzzlePage, error)) (puzzleFiles, error) {
+	files, err := findPuzzleFiles(year, day, getPage)
 	if err != nil {
-		return err
+		return puzzleFiles{}, err
 	}
+	return files, createFolders(files.dir)
+}
 
-	md, err := content.ToMarkdown(year)
+// findPuzzleFiles returns the files of the puzzle at its place in the layout without creating anything.
+// Outside of a workspace they are in the current folder.
+func findPuzzleFiles(year, day int, getPage func() (*aoc.PuzzlePage, error)) (puzzleFiles, error) {
+	l, err := currentLayout()
 	if err != nil {
-		return err
+		return puzzleFiles{}, err
 	}
-
-	err = writeStringToFile(filepath.Join(dir, "description.md"), md)
+	loc, err := locatePuzzle()
 	if err != nil {
-		return err
+		return puzzleFiles{}, err
+	}
+	if !loc.inWorkspace {
+		return filesInDir("."), nil
 	}
 
-	return nil
+	p := layout.Puzzle{Year: year, Day: day}
+	if l.UsesSlug() {
+		// in the folder of the puzzle the slug is already known
+		if loc.puzzle.Day == day && (loc.puzzle.Year == 0 || loc.puzzle.Year == year) && loc.puzzle.Slug != "" {
+			p.Slug = loc.puzzle.Slug
+		} else {
+			page, err := getPage()
+			if err != nil {
+				return puzzleFiles{}, err
+			}
+			p.Slug = layout.Slug(page.Title)
+		}
+	}
+
+	return layoutFiles(l, loc.root, p)
 }
 
-func downloadExample(year, day int, dir string) error {
-	content, err := client.GetExample(year, day)
-	if err != nil {
-		return err
-	}
+// saveDescription writes the description of the puzzle page as markdown into the file
+func saveDescription(w *puzzleWriter, getPage func() (*aoc.PuzzlePage, error), path string) error {
+	return w.writeData(path, func() (string, error) {
+		page, err := getPage()
+		if err != nil {
+			return "", err
+		}
+		return page.Markdown()
+	})
+}
 
-	err = writeStringToFile(filepath.Join(dir, "example"), content)
-	if err != nil {
-		return err
+// saveExample writes the first example of the puzzle page into the file
+func saveExample(w *puzzleWriter, getPage func() (*aoc.PuzzlePage, error), path string) error {
+	return w.writeData(path, func() (string, error) {
+		page, err := getPage()
+		if err != nil {
+			return "", err
+		}
+		return page.Example(), nil
+	})
+}
+
+func downloadInput(ctx context.Context, w *puzzleWriter, year, day int, path string) error {
+	return w.writeData(path, func() (string, error) {
+		content, err := client.GetInputContext(ctx, year, day)
+		return string(content), err
+	})
+}
+
+// overwriteMode is how new and download handle files which already exist
+type overwriteMode int
+
+const (
+	// keepFiles skips all existing files
+	keepFiles overwriteMode = iota
+	// updateData writes the puzzle data again, but keeps the solution files
+	updateData
+	// overwriteFiles overwrites all files
+	overwriteFiles
+)
+
+// addOverwriteFlags adds the flags for existing files to new and download
+func addOverwriteFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("force", "f", false, "overwrite existing files, including the solution files")
+	cmd.Flags().BoolP("update", "u", false, "download the description, examples and input again if they already exist, but keep the solution files")
+	cmd.MarkFlagsMutuallyExclusive("force", "update")
+}
+
+func getOverwriteMode(cmd *cobra.Command) overwriteMode {
+	if force, _ := cmd.Flags().GetBool("force"); force {
+		return overwriteFiles
 	}
+	if update, _ := cmd.Flags().GetBool("update"); update {
+		return updateData
+	}
+	return keepFiles
+}
 
-	return nil
+// puzzleWriter writes the files of a puzzle without destroying existing work and remembers what happened to them
+type puzzleWriter struct {
+	mode    overwriteMode
+	results []template.Result
 }
 
-func downloadInput(year, day int, dir string) error {
-	content, err := client.GetInput(year, day)
-	if err != nil {
-		return err
+// writeData writes a file of the puzzle data, the content is only requested if the file is written
+func (w *puzzleWriter) writeData(path string, content func() (string, error)) error {
+	status := template.Created
+	if _, err := os.Stat(path); err == nil {
+		if w.mode == keepFiles {
+			w.results = append(w.results, template.Result{Path: path, Status: template.Kept})
+			return nil
+		}
+		status = template.Overwritten
 	}
 
-	err = writeStringToFile(filepath.Join(dir, "input"), string(content))
+	c, err := content()
 	if err != nil {
 		return err
 	}
-
+	if err := writeStringToFile(path, c); err != nil {
+		return err
+	}
+	w.results = append(w.results, template.Result{Path: path, Status: status})
 	return nil
 }
 
+// printSummary prints which files were created, updated and skipped
+func (w *puzzleWriter) printSummary(cmd *cobra.Command) {
+	wd, _ := os.Getwd()
+	var created, updated, kept int
+	for _, r := range w.results {
+		path := r.Path
+		if rel, err := filepath.Rel(wd, path); err == nil {
+			path = rel
+		}
+
+		switch r.Status {
+		case template.Created:
+			created++
+			cmd.Println("  created ", path)
+		case template.Overwritten:
+			updated++
+			cmd.Println("  updated ", path)
+		case template.Kept:
+			kept++
+			cmd.Println("  skipped ", path)
+		}
+	}
+
+	cmd.Printf("%d created, %d updated, %d skipped\n", created, updated, kept)
+	switch {
+	case kept > 0 && w.mode == keepFiles:
+		cmd.Println("Existing files are kept, use --update to download the puzzle data again or --force to overwrite all files.")
+	case kept > 0 && w.mode == updateData:
+		cmd.Println("The solution files are kept, use --force to overwrite them as well.")
+	}
+}
+
 func contentFlagsChanged(cmd *cobra.Command) bool {
 	return cmd.Flag("description").Changed || cmd.Flag("examples").Changed || cmd.Flag("input").Changed
 }
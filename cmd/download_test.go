@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDownload(t *testing.T) {
+	s := newTestServer(t)
+	dir := t.TempDir()
+
+	out := runAocli(t, dir, "download", "-y", "2024", "-d", "1")
+	if !strings.Contains(out, "3 created, 0 updated, 0 skipped") {
+		t.Errorf("summary is missing:\n%s", out)
+	}
+	if input := readFile(t, filepath.Join(dir, "input")); input != "4\n5\n6\n" {
+		t.Errorf("input = %q, want %q", input, "4\n5\n6\n")
+	}
+	if example := readFile(t, filepath.Join(dir, "example")); example != "1\n2\n3\n" {
+		t.Errorf("example = %q, want %q", example, "1\n2\n3\n")
+	}
+	if description := readFile(t, filepath.Join(dir, "description.md")); !strings.Contains(description, "sum of all numbers") {
+		t.Errorf("description misses part one:\n%s", description)
+	}
+
+	// part two only gets into the existing description with --update
+	s.Puzzle(2024, 1).Solved = 1
+	out = runAocli(t, dir, "download", "-y", "2024", "-d", "1", "-D")
+	if !strings.Contains(out, "0 created, 0 updated, 1 skipped") {
+		t.Errorf("the existing description wasn't kept:\n%s", out)
+	}
+	if description := readFile(t, filepath.Join(dir, "description.md")); strings.Contains(description, "Part Two") {
+		t.Error("the existing description was overwritten without --update")
+	}
+
+	runAocli(t, dir, "download", "-y", "2024", "-d", "1", "-D", "--update")
+	if description := readFile(t, filepath.Join(dir, "description.md")); !strings.Contains(description, "product of all numbers") {
+		t.Errorf("description misses part two after --update:\n%s", description)
+	}
+}
+
+func TestNew(t *testing.T) {
+	newTestServer(t)
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".aocli.toml"), []byte("year = 2024\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "template"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "template", "main.go.tmpl"), []byte("// {{.Title}}\npackage {{.Package}}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// new works from any folder of the workspace and creates the puzzle at the root
+	sub := filepath.Join(root, "notes")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	out := runAocli(t, sub, "new", "-d", "1")
+	if !strings.Contains(out, "Finished successfully!") {
+		t.Fatalf("new failed:\n%s", out)
+	}
+
+	day := filepath.Join(root, "day01")
+	if main := readFile(t, filepath.Join(day, "main.go")); main != "// Puzzle 1\npackage day01\n" {
+		t.Errorf("main.go = %q", main)
+	}
+	if input := readFile(t, filepath.Join(day, "input")); input != "4\n5\n6\n" {
+		t.Errorf("input = %q, want %q", input, "4\n5\n6\n")
+	}
+
+	// the solution is kept when the day is created again
+	if err := os.WriteFile(filepath.Join(day, "main.go"), []byte("solved\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runAocli(t, root, "new", "-d", "1", "--update")
+	if main := readFile(t, filepath.Join(day, "main.go")); main != "solved\n" {
+		t.Errorf("the solution was overwritten with --update: %q", main)
+	}
+}
+
+func TestNewPostCreate(t *testing.T) {
+	newTestServer(t)
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".aocli.toml":            "year = 2024\n",
+		"template/main.go.tmpl":  "package {{.Package}}\n",
+		"template/template.toml": "post_create = [\"echo {{.Package}} > hook.txt\"]\n",
+	})
+
+	// without a terminal to ask the commands are only shown
+	out := runAocli(t, root, "new", "-d", "1")
+	if !strings.Contains(out, "echo day01 > hook.txt") || !strings.Contains(out, "--run-hooks") {
+		t.Errorf("the skipped command isn't shown:\n%s", out)
+	}
+	if exists(root, "day01/hook.txt") {
+		t.Fatal("the command ran without being allowed")
+	}
+
+	runAocli(t, root, "new", "-d", "1", "--force", "--run-hooks")
+	if hook := readFile(t, filepath.Join(root, "day01", "hook.txt")); strings.TrimSpace(hook) != "day01" {
+		t.Errorf("hook.txt = %q, want the output of the command", hook)
+	}
+}
+
+func TestInvalidConfigValueIsIgnored(t *testing.T) {
+	newTestServer(t)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".aocli.toml"), []byte("year = 2024\nrate_burst = -1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the invalid burst doesn't stop the download, it is reported and left out
+	out := runAocli(t, dir, "download", "-d", "1")
+	if !strings.Contains(out, "rate_burst: must not be negative") || !strings.Contains(out, "The invalid values are ignored") {
+		t.Errorf("the invalid value isn't reported:\n%s", out)
+	}
+	if input := readFile(t, filepath.Join(dir, "day01", "input")); input != "4\n5\n6\n" {
+		t.Errorf("input = %q, want %q", input, "4\n5\n6\n")
+	}
+}
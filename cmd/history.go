@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mitsimi/aocli/internal/aoc"
+	"github.com/mitsimi/aocli/internal/history"
+	"github.com/spf13/cobra"
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List the submitted answers",
+	Long: `List the answers submitted with aocli and what the site replied.
+Every profile has its own history, use the year and day flags to only list the answers of some puzzles.`,
+	Args: cobra.NoArgs,
+	RunE: executeHistory,
+	// the history is local, it needs no session
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return checkConfig(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().IntP("year", "y", 0, "only list the answers of puzzles from this year")
+	historyCmd.Flags().IntP("day", "d", 0, "only list the answers of puzzles from this day")
+}
+
+// openHistory returns the submission history in the aocli config directory, every profile has its own
+func openHistory() (*history.History, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find the config directory: %v", err)
+	}
+	if activeProfile != "" {
+		dir = filepath.Join(dir, "profiles", activeProfile)
+	}
+	return history.New(filepath.Join(dir, "history.jsonl")), nil
+}
+
+// recordSubmission adds the answer to the history, a failure is only reported because the answer was submitted anyway
+func recordSubmission(cmd *cobra.Command, level, year, day int, answer string, outcome aoc.SubmissionOutcome) {
+	h, err := openHistory()
+	if err == nil {
+		err = h.Add(history.Entry{Time: time.Now(), Year: year, Day: day, Level: level, Answer: answer, Outcome: outcome.String()})
+	}
+	if err != nil {
+		cmd.PrintErrf("Failed to save the answer in the history: %v\n", err)
+	}
+}
+
+func executeHistory(cmd *cobra.Command, args []string) error {
+	year, _ := cmd.Flags().GetInt("year")
+	if year != 0 && year < 100 {
+		year += 2000
+	}
+	day, _ := cmd.Flags().GetInt("day")
+	cmd.SilenceUsage = true
+
+	h, err := openHistory()
+	if err != nil {
+		return err
+	}
+	entries, err := h.List()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	n := 0
+	for _, e := range entries {
+		if (year != 0 && e.Year != year) || (day != 0 && e.Day != day) {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%d/%d\tlevel %d\t%s\t%s\n", e.Time.Local().Format(time.DateTime), e.Year, e.Day, e.Level, e.Answer, e.Outcome)
+		n++
+	}
+	if n == 0 {
+		cmd.Println("No answers were submitted yet.")
+		return nil
+	}
+	return w.Flush()
+}
@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mitsimi/aocli/internal/config"
+	"github.com/mitsimi/aocli/internal/template"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// configFormats are the formats a config file can be written in
+var configFormats = []string{"toml", "yaml", "json"}
+
+// noTemplate is the template language which creates an empty template folder
+const noTemplate = "none"
+
+// gitignoreEntries keep the puzzle data out of git, the AoC team asks not to publish it.
+// The ones with a * are the names used by layouts which name the solution file, e.g. 01.input.
+var gitignoreEntries = []string{"input", "description.md", "example", "*.input", "*.description.md", "*.example"}
+
+// initCmd represents the init command
+var initCmd = &cobra.Command{
+	Use:   "init [DIR]",
+	Short: "Set up a workspace for Advent of Code",
+	Long: `Set up a workspace for Advent of Code in the current folder or the given one.
+It writes the config file, creates the template folder with a starter for your language and adds a .gitignore which keeps the inputs and descriptions out of git.
+The settings are asked for interactively, unless they are given with the flags or --yes is used to take the defaults.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: executeInit,
+	// a workspace can be set up before there is a session or a working config
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+
+	initCmd.Flags().IntP("year", "y", 0, "year of the Advent of Code event (default the current or last event)")
+	initCmd.Flags().String("structure", "", "folder structure: "+strings.Join(config.Structures, " or ")+" (default single-year)")
+	initCmd.Flags().String("format", "", "format of the config file: "+strings.Join(configFormats, ", ")+" (default toml)")
+	initCmd.Flags().String("language", "", "language of the starter template: "+strings.Join(append(template.Starters(), noTemplate), ", ")+" (default none)")
+	initCmd.Flags().Bool("yes", false, "don't ask, use the defaults for the settings which aren't given with flags")
+	initCmd.Flags().Bool("force", false, "overwrite an existing config file")
+}
+
+// initSettings are the answers of the wizard
+type initSettings struct {
+	year      int
+	structure string
+	format    string
+	language  string
+}
+
+func executeInit(cmd *cobra.Command, args []string) error {
+	dir := "."
+	if len(args) == 1 {
+		dir = args[0]
+	}
+
+	settings := initSettings{
+		year:      getDefaultYear(),
+		structure: config.Structures[0],
+		format:    configFormats[0],
+		language:  noTemplate,
+	}
+	if cmd.Flag("year").Changed {
+		year, _ := cmd.Flags().GetInt("year")
+		if year < 100 {
+			year += 2000
+		}
+		settings.year = year
+	}
+	if cmd.Flag("structure").Changed {
+		settings.structure, _ = cmd.Flags().GetString("structure")
+	}
+	if cmd.Flag("format").Changed {
+		settings.format, _ = cmd.Flags().GetString("format")
+	}
+	if cmd.Flag("language").Changed {
+		settings.language, _ = cmd.Flags().GetString("language")
+	}
+
+	yes, _ := cmd.Flags().GetBool("yes")
+	if !yes && term.IsTerminal(int(os.Stdin.Fd())) {
+		if err := askInitSettings(cmd, &settings); err != nil {
+			return err
+		}
+	}
+
+	if err := settings.validate(); err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	force, _ := cmd.Flags().GetBool("force")
+	return writeWorkspace(cmd, dir, settings, force)
+}
+
+// askInitSettings asks for the settings which weren't given with flags
+func askInitSettings(cmd *cobra.Command, s *initSettings) error {
+	in := bufio.NewReader(cmd.InOrStdin())
+	out := cmd.OutOrStdout()
+
+	if !cmd.Flag("year").Changed {
+		answer, err := ask(in, out, "Year of the event", strconv.Itoa(s.year), nil)
+		if err != nil {
+			return err
+		}
+		year, err := strconv.Atoi(answer)
+		if err != nil {
+			return fmt.Errorf("invalid year: %s", answer)
+		}
+		if year < 100 {
+			year += 2000
+		}
+		s.year = year
+	}
+
+	var err error
+	if !cmd.Flag("structure").Changed {
+		if s.structure, err = ask(in, out, "Folder structure", s.structure, config.Structures); err != nil {
+			return err
+		}
+	}
+	if !cmd.Flag("format").Changed {
+		if s.format, err = ask(in, out, "Config format", s.format, configFormats); err != nil {
+			return err
+		}
+	}
+	if !cmd.Flag("language").Changed {
+		if s.language, err = ask(in, out, "Template language", s.language, append(template.Starters(), noTemplate)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ask prints the question and returns the answer, an empty answer takes the default.
+// If options are given the answer is asked for again until it is one of them.
+func ask(in *bufio.Reader, out io.Writer, question, def string, options []string) (string, error) {
+	for {
+		if len(options) > 0 {
+			fmt.Fprintf(out, "%s (%s) [%s]: ", question, strings.Join(options, ", "), def)
+		} else {
+			fmt.Fprintf(out, "%s [%s]: ", question, def)
+		}
+
+		line, err := in.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			return def, nil
+		}
+		if len(options) == 0 || slices.Contains(options, answer) {
+			return answer, nil
+		}
+
+		fmt.Fprintf(out, "Please answer with one of: %s\n", strings.Join(options, ", "))
+		if errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("no valid answer given")
+		}
+	}
+}
+
+func (s initSettings) validate() error {
+	if s.year < config.FirstEventYear || s.year > config.LatestEventYear() {
+		return fmt.Errorf("invalid year: %d (use a year from %d to %d)", s.year, config.FirstEventYear, config.LatestEventYear())
+	}
+	if !slices.Contains(config.Structures, s.structure) {
+		return fmt.Errorf("unknown structure: %s (use %s)", s.structure, strings.Join(config.Structures, " or "))
+	}
+	if !slices.Contains(configFormats, s.format) {
+		return fmt.Errorf("unknown config format: %s (use %s)", s.format, strings.Join(configFormats, ", "))
+	}
+	if s.language != noTemplate && !slices.Contains(template.Starters(), s.language) {
+		return fmt.Errorf("unknown template language: %s (use %s)", s.language, strings.Join(append(template.Starters(), noTemplate), ", "))
+	}
+	return nil
+}
+
+// writeWorkspace writes the config, the template folder and the .gitignore into the directory
+func writeWorkspace(cmd *cobra.Command, dir string, s initSettings, force bool) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, ".aocli."+s.format)
+
+	// only the file of the format is overwritten, a config in another format would still be found before or after it
+	for _, name := range projectConfigNames {
+		existing := filepath.Join(dir, name)
+		if _, err := os.Stat(existing); err != nil {
+			continue
+		}
+		if existing != path {
+			hint := "remove it first"
+			if format := configFormat(name); format != s.format {
+				hint += " or keep its format with --format " + format
+			}
+			return fmt.Errorf("%s already exists, %s", existing, hint)
+		}
+		if !force {
+			return fmt.Errorf("%s already exists, use --force to overwrite it", existing)
+		}
+	}
+
+	c := &config.Config{
+		Year:      s.year,
+		Structure: s.structure,
+	}
+	if err := c.Write(path); err != nil {
+		return fmt.Errorf("Failed to write the config: %v", err)
+	}
+	cmd.Println("Wrote", path)
+
+	templateDir := filepath.Join(dir, "template")
+	if template.FolderExists(templateDir) {
+		cmd.Println("Kept the existing", templateDir)
+	} else {
+		if err := os.MkdirAll(templateDir, 0o755); err != nil {
+			return err
+		}
+		if s.language != noTemplate {
+			if err := template.WriteStarter(s.language, templateDir); err != nil {
+				return fmt.Errorf("Failed to write the starter template: %v", err)
+			}
+		}
+		cmd.Println("Created", templateDir)
+	}
+
+	gitignore := filepath.Join(dir, ".gitignore")
+	added, err := addGitignoreEntries(gitignore, gitignoreEntries)
+	if err != nil {
+		return fmt.Errorf("Failed to update .gitignore: %v", err)
+	}
+	if added > 0 {
+		cmd.Println("Updated", gitignore)
+	}
+
+	cmd.Println("The workspace is ready, create the folder of a puzzle with \"aocli new\".")
+	return nil
+}
+
+// configFormat returns the format of the config file, the .yml files are yaml
+func configFormat(name string) string {
+	format := strings.TrimPrefix(filepath.Ext(name), ".")
+	if format == "yml" {
+		return "yaml"
+	}
+	return format
+}
+
+// addGitignoreEntries appends the entries which are missing in the .gitignore and returns how many were added
+func addGitignoreEntries(path string, entries []string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+
+	existing := strings.Split(string(data), "\n")
+	for i := range existing {
+		existing[i] = strings.TrimSpace(existing[i])
+	}
+
+	var missing []string
+	for _, entry := range entries {
+		if !slices.Contains(existing, entry) {
+			missing = append(missing, entry)
+		}
+	}
+	if len(missing) == 0 {
+		return 0, nil
+	}
+
+	var b strings.Builder
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString("# puzzle data of Advent of Code, which must not be shared\n")
+	for _, entry := range missing {
+		b.WriteString(entry + "\n")
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(b.String())
+	return len(missing), err
+}
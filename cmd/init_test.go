@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInit(t *testing.T) {
+	newTestServer(t)
+	dir := t.TempDir()
+
+	out := runAocli(t, dir, "init", "--yes", "-y", "23", "--structure", "multi-year", "--language", "go")
+	if !strings.Contains(out, "The workspace is ready") {
+		t.Errorf("init didn't finish:\n%s", out)
+	}
+	if config := readFile(t, filepath.Join(dir, ".aocli.toml")); config != "year = 2023\nstructure = \"multi-year\"\n" {
+		t.Errorf("config = %q", config)
+	}
+	if !exists(dir, "template/main.go.tmpl") {
+		t.Error("the starter of the language wasn't written")
+	}
+	gitignore := readFile(t, filepath.Join(dir, ".gitignore"))
+	for _, entry := range gitignoreEntries {
+		if !strings.Contains(gitignore, "\n"+entry+"\n") {
+			t.Errorf("%s is missing in the .gitignore:\n%s", entry, gitignore)
+		}
+	}
+
+	// a second init keeps the template and doesn't repeat the entries of the .gitignore
+	if err := os.WriteFile(filepath.Join(dir, "template", "main.go.tmpl"), []byte("mine\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runAocli(t, dir, "init", "--yes", "-y", "2024", "--force")
+	if config := readFile(t, filepath.Join(dir, ".aocli.toml")); config != "year = 2024\nstructure = \"single-year\"\n" {
+		t.Errorf("config after --force = %q", config)
+	}
+	if main := readFile(t, filepath.Join(dir, "template", "main.go.tmpl")); main != "mine\n" {
+		t.Errorf("the template was overwritten: %q", main)
+	}
+	if again := readFile(t, filepath.Join(dir, ".gitignore")); again != gitignore {
+		t.Errorf("the .gitignore changed:\n%s", again)
+	}
+}
+
+func TestInitExistingConfig(t *testing.T) {
+	newTestServer(t)
+
+	tests := []struct {
+		name  string
+		files []string
+		args  []string
+		ok    bool
+	}{
+		{"existing config", []string{".aocli.toml"}, nil, false},
+		{"existing config with --force", []string{".aocli.toml"}, []string{"--force"}, true},
+		{"config in another format", []string{".aocli.json"}, []string{"--force"}, false},
+		{"config with the other yaml extension", []string{".aocli.yml"}, []string{"--force", "--format", "yaml"}, false},
+		// only the names of the config are checked, e.g. a backup is no config
+		{"other files", []string{".aocli.toml.bak", ".aocli.local"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := make(map[string]string)
+			for _, name := range tt.files {
+				files[name] = "# mine\n"
+			}
+			writeFiles(t, dir, files)
+
+			out, err := runAocliErr(t, dir, append([]string{"init", "--yes", "-y", "2023"}, tt.args...)...)
+			if (err == nil) != tt.ok {
+				t.Fatalf("err = %v, want ok %v:\n%s", err, tt.ok, out)
+			}
+			for _, name := range tt.files {
+				// the file which is written may be overwritten, no other one
+				if tt.ok && name == ".aocli.toml" {
+					continue
+				}
+				if got := readFile(t, filepath.Join(dir, name)); got != "# mine\n" {
+					t.Errorf("%s was changed: %q", name, got)
+				}
+			}
+		})
+	}
+}
+
+func TestInitInvalidSettings(t *testing.T) {
+	newTestServer(t)
+	for _, args := range [][]string{
+		{"-y", "2014"},
+		{"--structure", "flat"},
+		{"--format", "ini"},
+		{"--language", "cobol"},
+	} {
+		dir := t.TempDir()
+		if _, err := runAocliErr(t, dir, append([]string{"init", "--yes"}, args...)...); err == nil {
+			t.Errorf("init %s was accepted", strings.Join(args, " "))
+		}
+		if exists(dir, ".aocli.toml") {
+			t.Errorf("init %s wrote the config", strings.Join(args, " "))
+		}
+	}
+}
+
+func TestAsk(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		options []string
+		want    string
+		ok      bool
+	}{
+		{"default", "\n", nil, "def", true},
+		{"default at the end of the input", "", nil, "def", true},
+		{"answer", " 2023 \n", nil, "2023", true},
+		{"option", "json\n", configFormats, "json", true},
+		{"asked again", "ini\nyaml\n", configFormats, "yaml", true},
+		{"no valid option", "ini", configFormats, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ask(bufio.NewReader(strings.NewReader(tt.input)), io.Discard, "Question", "def", tt.options)
+			if (err == nil) != tt.ok || got != tt.want {
+				t.Errorf("ask = %q, %v, want %q, ok %v", got, err, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestAddGitignoreEntries(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		added int
+		want  string
+	}{
+		{"new file", "", 2, "# puzzle data of Advent of Code, which must not be shared\ninput\n*.input\n"},
+		{"without newline at the end", "bin", 2, "bin\n# puzzle data of Advent of Code, which must not be shared\ninput\n*.input\n"},
+		{"one is there", "  input  \n", 1, "  input  \n# puzzle data of Advent of Code, which must not be shared\n*.input\n"},
+		{"all are there", "*.input\ninput\n", 0, "*.input\ninput\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".gitignore")
+			if tt.input != "" {
+				if err := os.WriteFile(path, []byte(tt.input), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			added, err := addGitignoreEntries(path, []string{"input", "*.input"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if added != tt.added {
+				t.Errorf("added = %d, want %d", added, tt.added)
+			}
+			if got := readFile(t, path); got != tt.want {
+				t.Errorf(".gitignore = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mitsimi/aocli/internal/config"
+	"github.com/mitsimi/aocli/internal/layout"
+	"github.com/spf13/cobra"
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move the puzzles of the workspace into another layout",
+	Long: `Move the puzzles of the workspace into another structure or layout.
+The puzzles are found with the current layout and moved with "git mv" inside of a git repository, so the history is kept.
+Afterwards the config of the project is changed to the new layout, outside of a project a .aocli.toml is created.
+If a move fails, the puzzles which were already moved are moved back.
+Puzzles of the single-year structure get the year from the --year flag or the config.`,
+	Example: `  aocli migrate --to multi-year --dry-run
+  aocli migrate --to-layout '{{.Year}}/day{{printf "%02d" .Day}}-{{.Slug}}'`,
+	Args: cobra.NoArgs,
+	RunE: executeMigrate,
+	// the titles for a layout with the slug can be read without a session
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkConfig(cmd); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+
+		// a dry run doesn't download the titles, so it doesn't ask for the session either
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			return nil
+		}
+
+		s, _, err := lookupSessionToken()
+		if err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+		client = newClient(s)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().String("to", "", "structure to move the puzzles into (single-year or multi-year)")
+	migrateCmd.Flags().String("to-layout", "", `layout to move the puzzles into, e.g. {{.Year}}/day{{printf "%02d" .Day}}-{{.Slug}}`)
+	migrateCmd.Flags().IntP("year", "y", 0, "year of the puzzles whose path has no year (default is the year of the config)")
+	migrateCmd.Flags().BoolP("dry-run", "n", false, "only print the planned moves")
+	migrateCmd.MarkFlagsMutuallyExclusive("to", "to-layout")
+	migrateCmd.MarkFlagsOneRequired("to", "to-layout")
+}
+
+// foundPuzzle is a puzzle found in the workspace and the path it was found at
+type foundPuzzle struct {
+	path   string
+	puzzle layout.Puzzle
+}
+
+// dryRunSlug is the slug of the paths printed by a dry run, the titles are only downloaded for the real migration
+const dryRunSlug = "<slug>"
+
+// puzzleMove is the move of one file or folder of a puzzle
+type puzzleMove struct {
+	from string
+	to   string
+}
+
+func executeMigrate(cmd *cobra.Command, args []string) error {
+	to, _ := cmd.Flags().GetString("to")
+	toLayout, _ := cmd.Flags().GetString("to-layout")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	pattern := toLayout
+	switch to {
+	case "":
+	case "single-year":
+		pattern = layout.SingleYear
+	case "multi-year":
+		pattern = layout.MultiYear
+	default:
+		return fmt.Errorf("invalid structure: %s, it must be one of %s", to, strings.Join(config.Structures, ", "))
+	}
+	target, err := layout.Parse(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid layout: %v", err)
+	}
+	cmd.SilenceUsage = true
+
+	source, err := currentLayout()
+	if err != nil {
+		return err
+	}
+	if source.IsFile() != target.IsFile() {
+		return fmt.Errorf("can't migrate between a layout of folders and one of files")
+	}
+
+	root, configPath, err := findProjectRoot()
+	if err != nil {
+		if root, err = os.Getwd(); err != nil {
+			return err
+		}
+		configPath = filepath.Join(root, projectConfigNames[0])
+	}
+
+	year, _ := cmd.Flags().GetInt("year")
+	if year == 0 {
+		year = conf.Year
+	}
+	if year != 0 && year < 100 {
+		year += 2000
+	}
+
+	skip := []string{filepath.Join(root, "template")}
+	for _, dir := range templateDirs(root) {
+		skip = append(skip, dir.Path)
+	}
+	puzzles, err := findPuzzles(source, root, skip)
+	if err != nil {
+		return err
+	}
+
+	var moves []puzzleMove
+	for _, found := range puzzles {
+		p := found.puzzle
+		if p.Year == 0 {
+			if year == 0 {
+				return fmt.Errorf("the year of day %d isn't in its path, set it with --year", p.Day)
+			}
+			p.Year = year
+		}
+		if target.UsesSlug() && p.Slug == "" && dryRun {
+			p.Slug = dryRunSlug
+		} else if target.UsesSlug() && p.Slug == "" {
+			page, err := client.GetPuzzlePageContext(cmd.Context(), p.Year, p.Day)
+			if err != nil {
+				return fmt.Errorf("Failed to get the title of day %d of %d: %v", p.Day, p.Year, err)
+			}
+			p.Slug = layout.Slug(page.Title)
+		}
+
+		pm, err := puzzleMoves(filesAt(source, found.path), target, root, p)
+		if err != nil {
+			return err
+		}
+		moves = append(moves, pm...)
+	}
+
+	if err := checkMoves(moves); err != nil {
+		return err
+	}
+
+	if len(moves) == 0 {
+		cmd.Println("No puzzles to move")
+	}
+
+	if dryRun {
+		for _, m := range moves {
+			cmd.Printf("Would move %s to %s\n", relPath(root, m.from), relPath(root, m.to))
+		}
+		if target.UsesSlug() {
+			cmd.Printf("The slugs shown as %s are made from the titles of the puzzles when they are moved\n", dryRunSlug)
+		}
+		cmd.Printf("Would set the layout %s in %s\n", target, configPath)
+		return nil
+	}
+
+	git := exec.Command("git", "-C", root, "rev-parse", "--is-inside-work-tree").Run() == nil
+	if err := applyMoves(cmd, root, moves, git); err != nil {
+		return err
+	}
+	if err := writeMigratedConfig(configPath, to, toLayout); err != nil {
+		err = fmt.Errorf("Failed to write %s: %v", configPath, err)
+		return errors.Join(err, undoMoves(cmd, root, moves, git))
+	}
+
+	for _, m := range moves {
+		removeEmptyParents(filepath.Dir(m.from), root)
+	}
+	cmd.Printf("Set the layout %s in %s\n", target, configPath)
+	return nil
+}
+
+// applyMoves moves the puzzles, if a move fails the ones before are moved back so the workspace stays as it was
+func applyMoves(cmd *cobra.Command, root string, moves []puzzleMove, git bool) error {
+	for i, m := range moves {
+		if err := movePath(root, m.from, m.to, git); err != nil {
+			err = fmt.Errorf("Failed to move %s to %s: %v", relPath(root, m.from), relPath(root, m.to), err)
+			return errors.Join(err, undoMoves(cmd, root, moves[:i], git))
+		}
+		cmd.Printf("Moved %s to %s\n", relPath(root, m.from), relPath(root, m.to))
+	}
+	return nil
+}
+
+// undoMoves moves the puzzles back in the reverse order and removes the folders the moves created
+func undoMoves(cmd *cobra.Command, root string, moves []puzzleMove, git bool) error {
+	var errs []error
+	for i := len(moves) - 1; i >= 0; i-- {
+		m := moves[i]
+		if err := movePath(root, m.to, m.from, git); err != nil {
+			errs = append(errs, fmt.Errorf("Failed to move %s back to %s: %v", relPath(root, m.to), relPath(root, m.from), err))
+			continue
+		}
+		removeEmptyParents(filepath.Dir(m.to), root)
+		cmd.Printf("Moved %s back to %s\n", relPath(root, m.to), relPath(root, m.from))
+	}
+	return errors.Join(errs...)
+}
+
+// relPath returns the path relative to the root for the messages
+func relPath(root, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// findPuzzles returns the puzzles which are at their place of the layout in the root folder.
+// The folders to skip are the ones of the templates.
+func findPuzzles(l *layout.Layout, root string, skip []string) ([]foundPuzzle, error) {
+	var puzzles []foundPuzzle
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		// the templates are no puzzles and .git has nothing to do with them
+		if d.IsDir() && (strings.HasPrefix(d.Name(), ".") || slices.Contains(skip, path)) {
+			return filepath.SkipDir
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		p, complete, ok := l.Match(rel)
+		if !ok {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if complete && d.IsDir() != l.IsFile() {
+			puzzles = append(puzzles, foundPuzzle{path: path, puzzle: p})
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+		}
+		return nil
+	})
+	return puzzles, err
+}
+
+// puzzleMoves returns the moves of the folder of the puzzle or of its solution and data files to their place in the target layout
+func puzzleMoves(from puzzleFiles, target *layout.Layout, root string, p layout.Puzzle) ([]puzzleMove, error) {
+	to, err := layoutFiles(target, root, p)
+	if err != nil {
+		return nil, err
+	}
+
+	if from.solution == "" {
+		if from.dir == to.dir {
+			return nil, nil
+		}
+		return []puzzleMove{{from: from.dir, to: to.dir}}, nil
+	}
+
+	var moves []puzzleMove
+	pairs := [][2]string{{from.solution, to.solution}, {from.description, to.description}, {from.example, to.example}, {from.input, to.input}}
+	for _, pair := range pairs {
+		if _, err := os.Stat(pair[0]); err == nil && pair[0] != pair[1] {
+			moves = append(moves, puzzleMove{from: pair[0], to: pair[1]})
+		}
+	}
+	return moves, nil
+}
+
+// checkMoves makes sure that no move overwrites a file or another moved puzzle before anything is moved
+func checkMoves(moves []puzzleMove) error {
+	var targets []string
+	for _, m := range moves {
+		if slices.Contains(targets, m.to) {
+			return fmt.Errorf("several puzzles would be moved to %s", m.to)
+		}
+		targets = append(targets, m.to)
+		if _, err := os.Stat(m.to); err == nil {
+			return fmt.Errorf("%s already exists", m.to)
+		}
+	}
+	return nil
+}
+
+// movePath moves the file or folder, with git if it contains tracked files
+func movePath(root, from, to string, git bool) error {
+	if err := os.MkdirAll(filepath.Dir(to), os.ModePerm); err != nil {
+		return err
+	}
+
+	// git mv refuses files which aren't tracked, e.g. the ignored inputs
+	if git && exec.Command("git", "-C", root, "ls-files", "--error-unmatch", "--", from).Run() == nil {
+		out, err := exec.Command("git", "-C", root, "mv", "--", from, to).CombinedOutput()
+		if err != nil {
+			return errors.New(strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+	return os.Rename(from, to)
+}
+
+// removeEmptyParents removes the folders left empty by the moves, e.g. the old year folders
+func removeEmptyParents(dir, root string) {
+	for dir != root && strings.HasPrefix(dir, root) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// writeMigratedConfig sets the new structure or layout in the config of the project
+func writeMigratedConfig(path, structure, pattern string) error {
+	c, err := readConfigFile(path)
+	if err != nil {
+		return err
+	}
+
+	if structure != "" {
+		c.Structure = structure
+		c.Layout = ""
+		// a layout from another config would still win over the structure
+		if origin := lastLayerWith("layout"); origin != "" && origin != "file:"+path {
+			c.Layout = layout.SingleYear
+			if structure == "multi-year" {
+				c.Layout = layout.MultiYear
+			}
+		}
+	} else {
+		c.Layout = pattern
+	}
+	return c.Update(path)
+}
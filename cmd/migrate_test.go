@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mitsimi/aocli/internal/layout"
+)
+
+// writeFiles creates the files, given by their slash separated path in the root, with their content
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// exists reports if the slash separated path exists in the root
+func exists(root, name string) bool {
+	_, err := os.Stat(filepath.Join(root, filepath.FromSlash(name)))
+	return err == nil
+}
+
+func TestFindPuzzles(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		files   []string
+		want    map[string]layout.Puzzle
+	}{
+		{"single-year", layout.SingleYear,
+			[]string{"day01/main.go", "day02/input", "day3/main.go", "notes/day04/main.go", "README.md"},
+			map[string]layout.Puzzle{"day01": {Day: 1}, "day02": {Day: 2}}},
+		{"multi-year", layout.MultiYear,
+			[]string{"2023/day01/main.go", "2024/day25/main.go", "2024/notes.md", "day01/main.go"},
+			map[string]layout.Puzzle{"2023/day01": {Year: 2023, Day: 1}, "2024/day25": {Year: 2024, Day: 25}}},
+		// the folders of the templates and hidden ones would match the slug
+		{"skipped folders", `{{.Slug}}/day{{printf "%02d" .Day}}`,
+			[]string{"aoc/day01/main.go", "templates/day02/main.go", "template/day03/main.go", ".cache/day04/main.go"},
+			map[string]layout.Puzzle{"aoc/day01": {Day: 1, Slug: "aoc"}}},
+		{"files", `{{.Year}}/day{{printf "%02d" .Day}}.py`,
+			[]string{"2024/day01.py", "2024/day01.input", "2024/day02.py/main.py", "2024/notes.txt"},
+			map[string]layout.Puzzle{"2024/day01.py": {Year: 2024, Day: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			files := make(map[string]string)
+			for _, name := range tt.files {
+				files[name] = ""
+			}
+			writeFiles(t, root, files)
+
+			l, err := layout.Parse(tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			skip := []string{filepath.Join(root, "template"), filepath.Join(root, "templates")}
+			puzzles, err := findPuzzles(l, root, skip)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make(map[string]layout.Puzzle)
+			for _, found := range puzzles {
+				got[filepath.ToSlash(relPath(root, found.path))] = found.puzzle
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("puzzles = %v, want %v", got, tt.want)
+			}
+			for path, p := range tt.want {
+				if got[path] != p {
+					t.Errorf("%s: puzzle = %+v, want %+v", path, got[path], p)
+				}
+			}
+		})
+	}
+}
+
+func TestPuzzleMoves(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"day01.py": "", "day01.input": "", "day02/main.go": ""})
+	single, _ := layout.Parse(layout.SingleYear)
+	multi, _ := layout.Parse(layout.MultiYear)
+	flatFiles, _ := layout.Parse(`day{{printf "%02d" .Day}}.py`)
+	yearFiles, _ := layout.Parse(`{{.Year}}/{{.Day}}.py`)
+
+	tests := []struct {
+		name   string
+		from   puzzleFiles
+		target *layout.Layout
+		p      layout.Puzzle
+		want   []string
+	}{
+		{"folder", filesAt(single, filepath.Join(root, "day02")), multi, layout.Puzzle{Year: 2024, Day: 2},
+			[]string{"day02 2024/day02"}},
+		{"same place", filesAt(single, filepath.Join(root, "day02")), single, layout.Puzzle{Year: 2024, Day: 2}, nil},
+		// only the files which exist are moved with the solution
+		{"files", filesAt(flatFiles, filepath.Join(root, "day01.py")), yearFiles, layout.Puzzle{Year: 2024, Day: 1},
+			[]string{"day01.py 2024/1.py", "day01.input 2024/1.input"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moves, err := puzzleMoves(tt.from, tt.target, root, tt.p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, m := range moves {
+				got = append(got, filepath.ToSlash(relPath(root, m.from))+" "+filepath.ToSlash(relPath(root, m.to)))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("moves = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckMoves(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"2024/day01/main.go": ""})
+	path := func(name string) string { return filepath.Join(root, filepath.FromSlash(name)) }
+
+	tests := []struct {
+		name  string
+		moves []puzzleMove
+		ok    bool
+	}{
+		{"free targets", []puzzleMove{{path("day01"), path("2023/day01")}, {path("day02"), path("2023/day02")}}, true},
+		{"existing target", []puzzleMove{{path("day01"), path("2024/day01")}}, false},
+		{"same target", []puzzleMove{{path("a/day01"), path("2023/day01")}, {path("b/day01"), path("2023/day01")}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkMoves(tt.moves); (err == nil) != tt.ok {
+				t.Errorf("err = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestMigrateGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	newTestServer(t)
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".aocli.toml":        "year = 2024\n",
+		"day01/main.go":      "package main\n",
+		"day01/input":        "4\n5\n6\n",
+		"template/main.tmpl": "",
+	})
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return string(out)
+	}
+	git("init", "-q")
+	git("add", ".aocli.toml", "day01/main.go")
+	git("-c", "user.name=aocli", "-c", "user.email=aocli@example.com", "commit", "-q", "-m", "day 1")
+
+	runAocli(t, root, "migrate", "--to", "multi-year")
+
+	// the tracked solution is renamed in git, the ignored input is moved as well
+	if status := git("status", "--porcelain", "--", "2024", "day01"); !strings.Contains(status, "R  day01/main.go -> 2024/day01/main.go") {
+		t.Errorf("the solution wasn't moved with git:\n%s", status)
+	}
+	if !exists(root, "2024/day01/input") || exists(root, "day01") || !exists(root, "template/main.tmpl") {
+		t.Error("the puzzle folder wasn't moved as a whole")
+	}
+	if config := readFile(t, filepath.Join(root, ".aocli.toml")); config != "year = 2024\nstructure = \"multi-year\"\n" {
+		t.Errorf("config = %q", config)
+	}
+}
+
+func TestMigrateRollback(t *testing.T) {
+	newTestServer(t)
+	root := t.TempDir()
+	config := "structure = \"multi-year\"\n"
+	writeFiles(t, root, map[string]string{
+		".aocli.toml":        config,
+		"2023/day01/main.go": "",
+		"2024/day01/main.go": "",
+		// the folder of the second move can't be created
+		"y2024": "",
+	})
+
+	out, err := runAocliErr(t, root, "migrate", "--to-layout", `y{{.Year}}/day{{printf "%02d" .Day}}`)
+	if err == nil {
+		t.Fatalf("the migration didn't fail:\n%s", out)
+	}
+	if !strings.Contains(out, filepath.FromSlash("Moved y2023/day01 back to 2023/day01")) {
+		t.Errorf("the first move wasn't undone:\n%s", out)
+	}
+	if !exists(root, "2023/day01/main.go") || !exists(root, "2024/day01/main.go") || exists(root, "y2023") {
+		t.Error("the workspace isn't as it was before the migration")
+	}
+	if got := readFile(t, filepath.Join(root, ".aocli.toml")); got != config {
+		t.Errorf("the config was changed: %q", got)
+	}
+}
+
+func TestMigrateDryRun(t *testing.T) {
+	s := newTestServer(t)
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{".aocli.toml": "year = 2024\n", "day01/main.go": ""})
+
+	out := runAocli(t, root, "migrate", "-n", "--to-layout", `{{.Year}}/day{{printf "%02d" .Day}}-{{.Slug}}`)
+	if !strings.Contains(out, filepath.FromSlash("Would move day01 to 2024/day01-<slug>")) {
+		t.Errorf("the planned move is missing:\n%s", out)
+	}
+	if sessionLookup.done || len(s.Requests()) != 0 {
+		t.Errorf("the dry run looked up the session or sent requests: %v", s.Requests())
+	}
+	if !exists(root, "day01/main.go") || exists(root, "2024") {
+		t.Error("the dry run moved the puzzle")
+	}
+
+	// the real migration gets the slug from the title
+	runAocli(t, root, "migrate", "--to-layout", `{{.Year}}/day{{printf "%02d" .Day}}-{{.Slug}}`)
+	if !exists(root, "2024/day01-puzzle-1/main.go") {
+		t.Error("the puzzle wasn't moved to the folder with the slug")
+	}
+}
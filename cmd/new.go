@@ -1,24 +1,34 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
-	"regexp"
-	"strconv"
+	"runtime"
+	"slices"
 
+	"github.com/mitsimi/aocli/internal/aoc"
+	"github.com/mitsimi/aocli/internal/layout"
 	"github.com/mitsimi/aocli/internal/template"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 // newCmd represents the new command
 var newCmd = &cobra.Command{
 	Use:   "new",
 	Short: "Creates a new folder for the day",
-	Long: `Creates a new folder with the contents of the template folder if present.
-It will save the description, examples and inputs automatically into seperate files.`,
+	Long: `Creates a new folder with the contents of the template given with --template or the default_template of the config.
+Without one the template folder of the workspace is used if present.
+It will save the description, examples and inputs automatically into seperate files.
+The path of the folder is given by the layout of the config, e.g. {{.Year}}/day{{printf "%02d" .Day}}-{{.Slug}}.
+Running it again for the same day keeps all existing files, --update downloads the puzzle data again and --force overwrites the solution files as well.`,
 	Args: cobra.NoArgs,
-	Run:  executeNew,
+	RunE: executeNew,
 }
 
 func init() {
@@ -26,74 +36,231 @@ func init() {
 
 	newCmd.Flags().IntP("year", "y", 0, "puzzle year (year of current or last event. Can be specified in the config file)")
 	newCmd.Flags().IntP("day", "d", 0, "puzzle day (current/last unlocked day (during Advent of Code month) or is inferred from the current folder)")
+	addOverwriteFlags(newCmd)
+	newCmd.Flags().StringP("template", "t", "", "name of the template from the templates folder of the project, the global one or the built-in ones")
+	newCmd.Flags().Bool("run-hooks", false, "run the post-create commands of the template without asking")
 }
 
-func executeNew(cmd *cobra.Command, args []string) {
+func executeNew(cmd *cobra.Command, args []string) error {
 	year := getYear(cmd)
 	day := getDay(cmd)
+	cmd.SilenceUsage = true
 
-	currentDir, err := os.Getwd()
+	l, err := currentLayout()
 	if err != nil {
-		cmd.PrintErrln("Failed to get current directory:", err)
-		return
+		return fmt.Errorf("Invalid layout: %v", err)
 	}
 
-	dayFolder := fmt.Sprintf("day%02d", day)
-	targetDir := currentDir
-
-	// if the current directory is a day folder, then we must set the target directory to the parent folder so we don't create a nested day folder
-	if regexp.MustCompile(`day\d{2}`).MatchString(filepath.Base(currentDir)) {
-		targetDir = filepath.Dir(currentDir)
+	// the puzzle folders are always created at the root of the workspace, so new works from any subfolder
+	loc, err := locatePuzzle()
+	if err != nil {
+		return fmt.Errorf("Failed to get current directory: %v", err)
 	}
 
-	if conf.Structure == "multi-year" {
-		yearReg := regexp.MustCompile(`\d{4}`)
-		if yearReg.MatchString(filepath.Base(currentDir)) { // check if the current directory is a year folder
-			// if yes we set the year to the year of the current directory
-			year, _ = strconv.Atoi(yearReg.FindString(filepath.Base(currentDir)))
-		} else {
-			// if not we set the target directory to the year folder
-			targetDir = filepath.Join(targetDir, fmt.Sprintf("%d", year))
+	tmpl, useTemplate, err := newTemplate(cmd, loc.root)
+	if err != nil {
+		return fmt.Errorf("Failed to find the template: %v", err)
+	}
+	manifest := &template.Manifest{}
+	if useTemplate {
+		if manifest, err = template.LoadManifest(tmpl.FS); err != nil {
+			return fmt.Errorf("Invalid template: %v", err)
 		}
 	}
 
-	targetDir = filepath.Join(targetDir, dayFolder)
+	// the page is needed first, because the title may be part of the path
+	cmd.Println("Downloading puzzle data...")
+	page, err := client.GetPuzzlePageContext(cmd.Context(), year, day)
+	if err != nil {
+		return fmt.Errorf("Failed to download puzzle data: %v", err)
+	}
 
-	if err := createFolders(targetDir); err != nil {
-		cmd.PrintErrln("Failed to create folders:", err)
-		return
+	files, err := layoutFiles(l, loc.root, layout.Puzzle{Year: year, Day: day, Slug: layout.Slug(page.Title)})
+	if err != nil {
+		return fmt.Errorf("Failed to create folders: %v", err)
+	}
+	if err := createFolders(files.dir); err != nil {
+		return fmt.Errorf("Failed to create folders: %v", err)
 	}
 
-	templateDir := filepath.Join(currentDir, "template")
-	if _, err := os.Stat(templateDir); !os.IsNotExist(err) {
+	w := &puzzleWriter{mode: getOverwriteMode(cmd)}
+	data := templateData(year, day, page, files)
+	if useTemplate {
 		cmd.Println("Copying template files...")
-		if err := template.CopyContent(templateDir, targetDir); err != nil {
-			cmd.PrintErrln("Failed to copy template files:", err)
-			return
+		if err := copyTemplate(w, tmpl.FS, manifest, files, data); err != nil {
+			return fmt.Errorf("Failed to copy template files: %v", err)
 		}
 	}
+	templateResults := w.results
 
-	cmd.Println("Downloading puzzle data...")
-	if err := downloadPuzzleData(year, day, targetDir); err != nil {
-		cmd.PrintErrln("Failed to download puzzle data:", err)
-		return
+	if err := downloadPuzzleData(cmd.Context(), w, page, year, day, files); err != nil {
+		return fmt.Errorf("Failed to download puzzle data: %v", err)
+	}
+
+	// the hooks run last, so they see the files of the template and the input
+	if err := applyManifest(cmd, w, manifest, loc.root, files.dir, data, templateResults); err != nil {
+		return err
 	}
 
+	w.printSummary(cmd)
 	cmd.Println("Finished successfully!")
+	return nil
+}
+
+// newTemplate returns the template from the flag or the config, without a name the template folder of the workspace is used
+func newTemplate(cmd *cobra.Command, root string) (template.Template, bool, error) {
+	name, _ := cmd.Flags().GetString("template")
+	if name == "" {
+		name = conf.DefaultTemplate
+	}
+	if name != "" {
+		t, err := template.Find(name, templateDirs(root)...)
+		return t, err == nil, err
+	}
+
+	dir := filepath.Join(root, "template")
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return template.Template{Name: "template", Source: template.SourceProject, Path: dir, FS: os.DirFS(dir)}, true, nil
+	}
+	return template.Template{}, false, nil
+}
+
+// copyTemplate copies the template into the folder of the puzzle, the .tmpl files are rendered with the data of the puzzle.
+// If the layout names the solution file, only the template file with its extension is copied to it.
+// Existing files are only overwritten with --force, because they hold the solution.
+func copyTemplate(w *puzzleWriter, src fs.FS, manifest *template.Manifest, files puzzleFiles, data template.Data) error {
+	overwrite := w.mode == overwriteFiles
+	if files.solution == "" {
+		results, err := template.CopyContent(src, manifest, files.dir, data, overwrite)
+		w.results = append(w.results, results...)
+		return err
+	}
+
+	ext := filepath.Ext(files.solution)
+	for _, pattern := range []string{"*" + ext + template.Suffix, "*" + ext} {
+		matches, err := fs.Glob(src, pattern)
+		if err != nil {
+			return err
+		}
+		if len(matches) > 0 {
+			status, err := template.CopyFile(src, matches[0], files.solution, data, manifest.Renders(matches[0]), overwrite)
+			if err == nil {
+				w.results = append(w.results, template.Result{Path: files.solution, Status: status})
+			}
+			return err
+		}
+	}
+	return nil
 }
 
-func downloadPuzzleData(year, day int, destDir string) (err error) {
-	err = downloadDescription(year, day, destDir)
+// applyManifest inserts the snippets of the manifest into the files of the workspace and runs its post-create commands in the folder of the puzzle.
+// The commands only run if files of the template were written, so a puzzle which already exists isn't set up again.
+// A template may come from anywhere, so the commands are shown and only run if they are confirmed or allowed with --run-hooks.
+func applyManifest(cmd *cobra.Command, w *puzzleWriter, manifest *template.Manifest, root, dir string, data template.Data, templateResults []template.Result) error {
+	written := slices.ContainsFunc(templateResults, func(r template.Result) bool {
+		return r.Status != template.Kept
+	})
+
+	changed, err := manifest.AppendSnippets(root, data)
+	for _, file := range changed {
+		w.results = append(w.results, template.Result{Path: filepath.Join(root, file), Status: template.Overwritten})
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to append to a file: %v", err)
+	}
+
+	commands, err := manifest.Commands(data)
+	if err != nil {
+		return fmt.Errorf("Invalid post-create command: %v", err)
+	}
+	if !written && len(commands) > 0 {
+		cmd.Println("Skipped the post-create commands, the files of the template already existed")
+		return nil
+	}
+	if ok, err := confirmCommands(cmd, commands, dir); err != nil || !ok {
+		return err
+	}
+	for _, command := range commands {
+		cmd.Println("Running", command)
+		c := shellCommand(command)
+		c.Dir = dir
+		c.Stdout = cmd.OutOrStdout()
+		c.Stderr = cmd.ErrOrStderr()
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("Failed to run %s: %v", command, err)
+		}
+	}
+	return nil
+}
+
+// confirmCommands reports if the post-create commands may run, without --run-hooks they are printed and the user is asked.
+// Without a terminal to ask they are skipped.
+func confirmCommands(cmd *cobra.Command, commands []string, dir string) (bool, error) {
+	if len(commands) == 0 {
+		return false, nil
+	}
+	if run, _ := cmd.Flags().GetBool("run-hooks"); run {
+		return true, nil
+	}
+
+	cmd.Println("The template has post-create commands to run in", dir+":")
+	for _, command := range commands {
+		cmd.Println("  " + command)
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		cmd.Println("Skipped the post-create commands, allow them with --run-hooks")
+		return false, nil
+	}
+
+	answer, err := ask(bufio.NewReader(cmd.InOrStdin()), cmd.OutOrStdout(), "Run them?", "n", []string{"y", "n"})
+	if err != nil {
+		return false, err
+	}
+	if answer != "y" {
+		cmd.Println("Skipped the post-create commands")
+		return false, nil
+	}
+	return true, nil
+}
+
+// shellCommand runs the command line with the shell of the system
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
+
+// templateData returns the variables of the template files for the puzzle
+func templateData(year, day int, page *aoc.PuzzlePage, files puzzleFiles) template.Data {
+	return template.Data{
+		Year:      year,
+		Day:       day,
+		DayPadded: fmt.Sprintf("%02d", day),
+		Title:     page.Title,
+		Slug:      layout.Slug(page.Title),
+		Package:   fmt.Sprintf("day%02d", day),
+		Input:     filepath.Base(files.input),
+		URL:       client.DayURL(year, day),
+	}
+}
+
+func downloadPuzzleData(ctx context.Context, w *puzzleWriter, page *aoc.PuzzlePage, year, day int, files puzzleFiles) (err error) {
+	getPage := func() (*aoc.PuzzlePage, error) {
+		return page, nil
+	}
+
+	err = saveDescription(w, getPage, files.description)
 	if err != nil {
 		return err
 	}
 
-	err = downloadInput(year, day, destDir)
+	err = downloadInput(ctx, w, year, day, files.input)
 	if err != nil {
 		return err
 	}
 
-	err = downloadExample(year, day, destDir)
+	err = saveExample(w, getPage, files.example)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/mitsimi/aocli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// profileCmd represents the profile command
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage the profiles of your accounts",
+	Long: `Manage the profiles of your accounts.
+A profile holds the session, year and structure of an account. Use it with the --profile flag or make it the default with "profile use".
+The profiles are stored in the global config file. Every profile has its own cache and history of submitted answers.`,
+	// the profiles have to be manageable without a session and even if the active profile is broken
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all profiles",
+	Args:  cobra.NoArgs,
+	RunE:  executeProfileList,
+}
+
+var profileAddCmd = &cobra.Command{
+	Use:   "add NAME",
+	Short: "Add a profile or update an existing one",
+	Args:  cobra.ExactArgs(1),
+	RunE:  executeProfileAdd,
+}
+
+var profileRemoveCmd = &cobra.Command{
+	Use:   "remove NAME",
+	Short: "Remove a profile",
+	Args:  cobra.ExactArgs(1),
+	RunE:  executeProfileRemove,
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use NAME",
+	Short: "Use the profile by default",
+	Args:  cobra.ExactArgs(1),
+	RunE:  executeProfileUse,
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileListCmd, profileAddCmd, profileRemoveCmd, profileUseCmd)
+
+	profileAddCmd.Flags().String("session", "", "session cookie of the account")
+	profileAddCmd.Flags().String("session-file", "", "file containing the session cookie of the account")
+	profileAddCmd.Flags().IntP("year", "y", 0, "year of the Advent of Code event")
+	profileAddCmd.Flags().String("structure", "", "folder structure (single-year or multi-year)")
+}
+
+// editGlobalConfig applies the change to the global config file and writes it back
+func editGlobalConfig(change func(c *config.Config) error) (string, error) {
+	path, err := globalConfigPath()
+	if err != nil {
+		return "", err
+	}
+
+	c, err := readConfigFile(path)
+	if err != nil {
+		return "", fmt.Errorf("Failed to read %s: %v", path, err)
+	}
+
+	if err := change(c); err != nil {
+		return "", err
+	}
+
+	return path, c.Update(path)
+}
+
+func executeProfileList(cmd *cobra.Command, args []string) error {
+	if len(conf.Profiles) == 0 {
+		cmd.Println("No profiles configured. Add one with \"aocli profile add NAME\".")
+		return nil
+	}
+
+	names := make([]string, 0, len(conf.Profiles))
+	for name := range conf.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tNAME\tYEAR\tSTRUCTURE\tSESSION")
+	for _, name := range names {
+		p := conf.Profiles[name]
+
+		active := ""
+		if name == activeProfile {
+			active = "*"
+		}
+
+		year := ""
+		if p.Year != 0 {
+			year = fmt.Sprint(p.Year)
+		}
+
+		// never print the session itself
+		session := "-"
+		switch {
+		case p.SessionFile != "":
+			session = "file " + p.SessionFile
+		case p.Session != "":
+			session = "set"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", active, name, year, p.Structure, session)
+	}
+	return w.Flush()
+}
+
+func executeProfileAdd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	path, err := editGlobalConfig(func(c *config.Config) error {
+		if c.Profiles == nil {
+			c.Profiles = make(map[string]config.Profile)
+		}
+
+		p := c.Profiles[name]
+		if cmd.Flag("session").Changed {
+			p.Session, _ = cmd.Flags().GetString("session")
+		}
+		if cmd.Flag("session-file").Changed {
+			p.SessionFile, _ = cmd.Flags().GetString("session-file")
+		}
+		if cmd.Flag("year").Changed {
+			p.Year, _ = cmd.Flags().GetInt("year")
+		}
+		if cmd.Flag("structure").Changed {
+			p.Structure, _ = cmd.Flags().GetString("structure")
+		}
+		c.Profiles[name] = p
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("Saved profile %s in %s\n", name, path)
+	return nil
+}
+
+func executeProfileRemove(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	path, err := editGlobalConfig(func(c *config.Config) error {
+		if _, ok := c.Profiles[name]; !ok {
+			return fmt.Errorf("unknown profile: %s", name)
+		}
+		delete(c.Profiles, name)
+
+		if c.Profile == name {
+			c.Profile = ""
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("Removed profile %s from %s\n", name, path)
+	return nil
+}
+
+func executeProfileUse(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	// the profile may also be defined in the project config, so the merged config is checked
+	if _, ok := conf.Profiles[name]; !ok {
+		return fmt.Errorf("unknown profile: %s", name)
+	}
+
+	path, err := editGlobalConfig(func(c *config.Config) error {
+		c.Profile = name
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("Using profile %s by default (saved in %s)\n", name, path)
+	return nil
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mitsimi/aocli/internal/layout"
+)
+
+// projectConfigNames are the names of the config file of a project, in the order they are looked for
+var projectConfigNames = []string{".aocli.toml", ".aocli.yaml", ".aocli.yml", ".aocli.json"}
+
+// globalConfigNames are the names of the config file in the aocli config directory
+var globalConfigNames = []string{"config.toml", "config.yaml", "config.yml", "config.json"}
+
+// configDir returns the aocli directory in $XDG_CONFIG_HOME or in the config directory of the system
+func configDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "aocli"), nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "aocli"), nil
+}
+
+// globalConfigFiles returns the existing global config files in the order they are merged.
+// The config in the home folder is still read, the one in the config directory wins.
+func globalConfigFiles() []string {
+	var files []string
+	if home, err := os.UserHomeDir(); err == nil {
+		if path, err := findConfigInDir(home); err == nil {
+			files = append(files, path)
+		}
+	}
+	if dir, err := configDir(); err == nil {
+		if path, err := findConfigFile(dir, globalConfigNames); err == nil {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// findConfigFile returns the first file of the names which exists in the directory
+func findConfigFile(dir string, names []string) (string, error) {
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+	return "", os.ErrNotExist
+}
+
+// findProjectRoot walks up from the current folder and returns the first folder with a project config.
+// The walk ends at the root of a git repository and before the home folder, whose config is the global one.
+func findProjectRoot() (root string, configPath string, err error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", "", err
+	}
+	home, _ := os.UserHomeDir()
+
+	for dir := wd; ; dir = filepath.Dir(dir) {
+		if dir == home {
+			break
+		}
+		if path, err := findConfigFile(dir, projectConfigNames); err == nil {
+			return dir, path, nil
+		}
+		if isGitRoot(dir) || dir == filepath.Dir(dir) {
+			break
+		}
+	}
+
+	return "", "", errors.New("No project found")
+}
+
+func isGitRoot(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// currentLayout returns the layout from the config, without one it is the one of the structure
+func currentLayout() (*layout.Layout, error) {
+	pattern := conf.Layout
+	if pattern == "" {
+		pattern = layout.SingleYear
+		if conf.Structure == "multi-year" {
+			pattern = layout.MultiYear
+		}
+	}
+	return layout.Parse(pattern)
+}
+
+// yearDirPattern matches a folder named after the year, like the ones of the multi-year structure
+var yearDirPattern = regexp.MustCompile(`^\d{4}$`)
+
+// findYearDir returns the year of the nearest folder named after a year the current folder is in.
+// In a project the search ends at its root, outside of one at the parent of the current folder.
+// It is the fallback for layouts without the year, e.g. a single-year project in a 2023 folder.
+func findYearDir() (year int, ok bool) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return 0, false
+	}
+	last := filepath.Dir(wd)
+	if root, _, err := findProjectRoot(); err == nil {
+		last = root
+	}
+
+	for dir := wd; ; dir = filepath.Dir(dir) {
+		if yearDirPattern.MatchString(filepath.Base(dir)) {
+			year, _ := strconv.Atoi(filepath.Base(dir))
+			return year, true
+		}
+		if dir == last || dir == filepath.Dir(dir) {
+			return 0, false
+		}
+	}
+}
+
+// puzzleLocation is the place of the current folder in the layout of the workspace
+type puzzleLocation struct {
+	// root is the folder the layout starts in
+	root string
+	// dir is the folder of the puzzle the current folder is in, it is empty outside of a puzzle folder
+	dir string
+	// puzzle has the values read from the path, they are zero if the path doesn't contain them
+	puzzle layout.Puzzle
+	// inWorkspace is set in a project or if a part of the current path matches the layout
+	inWorkspace bool
+}
+
+// locatePuzzle reads the year and day from the path of the current folder with the layout.
+// In a project the layout starts at its root, outside of one at the folder above the part of the path which matches.
+func locatePuzzle() (puzzleLocation, error) {
+	l, err := currentLayout()
+	if err != nil {
+		return puzzleLocation{}, err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return puzzleLocation{}, err
+	}
+
+	if root, _, err := findProjectRoot(); err == nil {
+		loc, _ := matchLayout(l, root, wd)
+		loc.inWorkspace = true
+		return loc, nil
+	}
+
+	var partial *puzzleLocation
+	root := wd
+	for i := 0; i <= l.Depth(); i++ {
+		if loc, ok := matchLayout(l, root, wd); ok {
+			if loc.dir != "" {
+				return loc, nil
+			}
+			if partial == nil {
+				partial = &loc
+			}
+		}
+		if root == filepath.Dir(root) {
+			break
+		}
+		root = filepath.Dir(root)
+	}
+	if partial != nil {
+		return *partial, nil
+	}
+	return puzzleLocation{root: wd}, nil
+}
+
+// matchLayout matches the path from the root to the current folder or the nearest parent which fits the layout.
+// Subfolders of a puzzle folder, e.g. src, are not part of the layout, so the parents are tried as well.
+func matchLayout(l *layout.Layout, root, wd string) (puzzleLocation, bool) {
+	for dir := wd; dir != root; dir = filepath.Dir(dir) {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil || !filepath.IsLocal(rel) {
+			break
+		}
+		if p, complete, ok := l.Match(rel); ok {
+			loc := puzzleLocation{root: root, puzzle: p, inWorkspace: true}
+			if complete {
+				loc.dir = dir
+			}
+			return loc, true
+		}
+	}
+	return puzzleLocation{root: root}, false
+}
+
+// puzzleFiles are the paths of the files of a puzzle
+type puzzleFiles struct {
+	// dir is the folder of the puzzle, it is shared with other puzzles if the layout names a file
+	dir string
+	// solution is the file named by the layout, it is empty if the layout names a folder
+	solution    string
+	description string
+	example     string
+	input       string
+}
+
+// filesInDir returns the files of a puzzle which has a folder of its own
+func filesInDir(dir string) puzzleFiles {
+	return puzzleFiles{
+		dir:         dir,
+		description: filepath.Join(dir, "description.md"),
+		example:     filepath.Join(dir, "example"),
+		input:       filepath.Join(dir, "input"),
+	}
+}
+
+// layoutFiles returns the files of the puzzle at its place in the layout.
+// If the layout names the solution file, e.g. src/bin/{{.Day}}.rs, the data files are put next to it, named like 01.input.
+func layoutFiles(l *layout.Layout, root string, p layout.Puzzle) (puzzleFiles, error) {
+	rel, err := l.Path(p)
+	if err != nil {
+		return puzzleFiles{}, err
+	}
+	return filesAt(l, filepath.Join(root, rel)), nil
+}
+
+// filesAt returns the files of the puzzle whose folder or solution file, depending on the layout, is at the path
+func filesAt(l *layout.Layout, path string) puzzleFiles {
+	if !l.IsFile() {
+		return filesInDir(path)
+	}
+
+	dir := filepath.Dir(path)
+	stem := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return puzzleFiles{
+		dir:         dir,
+		solution:    path,
+		description: filepath.Join(dir, stem+".description.md"),
+		example:     filepath.Join(dir, stem+".example"),
+		input:       filepath.Join(dir, stem+".input"),
+	}
+}
@@ -1,23 +1,46 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"strings"
+	"runtime/debug"
+	"syscall"
+	"time"
 
 	"github.com/mitsimi/aocli/internal/aoc"
 	"github.com/mitsimi/aocli/internal/config"
 	"github.com/spf13/cobra"
 )
 
+// Version is the version of the build, it is set at release time with
+// -ldflags "-X github.com/mitsimi/aocli/cmd.Version=v1.2.3"
+var Version = "dev"
+
 var cfgFlag string
 var sessionFlag string
+var noCacheFlag bool
+var verboseFlag bool
+var recordFlag string
+var replayFlag string
+var profileFlag string
 
 var conf *config.Config
 var client *aoc.Client
 
+// activeProfile is the name of the profile in use, it is empty if no profile is used
+var activeProfile string
+
+// configErr is the error which occurred while loading the config, it is reported before a command runs
+var configErr error
+
+// configWarnings are the problems of the values which were left out of the config, they are reported before a command runs
+var configWarnings []error
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "aocli",
@@ -25,8 +48,20 @@ var rootCmd = &cobra.Command{
 	Long: `aocli is a convenient cli tool for Advent of Code so you never have to leave your editor.
 It automatically can retreive the puzzle description and input and submit your answer.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		if s := getSessionToken(); s != "" {
-			client = aoc.NewClient(s)
+		if err := checkConfig(cmd); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+
+		s, _, err := lookupSessionToken()
+		if err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+
+		if s != "" || replayFlag != "" {
+			// the recorded responses don't need a session, the cookies are redacted anyway
+			client = newClient(s)
 			return nil
 		}
 
@@ -38,106 +73,240 @@ It automatically can retreive the puzzle description and input and submit your a
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	rootCmd.Execute()
-	fmt.Println("")
+	// cancel running requests on Ctrl-C instead of killing the program in the middle of writing files
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		// the error was already printed by cobra, the status is for scripts, CI and hooks
+		stop()
+		os.Exit(1)
+	}
 }
 
 func init() {
+	// builds with go install don't get the version stamped in, but know the version of the module
+	if info, ok := debug.ReadBuildInfo(); ok && Version == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		Version = info.Main.Version
+	}
+	rootCmd.Version = Version
+
 	cobra.OnInitialize(initConfig)
 	rootCmd.PersistentFlags().StringVarP(&cfgFlag, "config", "c", "", "config file")
 	rootCmd.PersistentFlags().StringVarP(&sessionFlag, "session", "s", "", "session cookie from adventofcode.com")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "name of the account profile from the config to use")
+	rootCmd.PersistentFlags().BoolVarP(&verboseFlag, "verbose", "v", false, "print details about the requests to adventofcode.com")
+	rootCmd.PersistentFlags().BoolVar(&noCacheFlag, "no-cache", false, "always request the puzzle data from adventofcode.com instead of the local cache")
+	rootCmd.PersistentFlags().StringVar(&recordFlag, "record", "", "save all requests and responses with redacted cookies and inputs as fixtures in this directory")
+	rootCmd.PersistentFlags().StringVar(&replayFlag, "replay", "", "answer all requests with the fixtures saved with --record in this directory")
+	rootCmd.MarkFlagsMutuallyExclusive("record", "replay")
 }
 
-func initConfig() {
-	conf = &config.Config{}
+// newClient creates the client for adventofcode.com with the options set by the flags
+func newClient(token string) *aoc.Client {
+	var options []aoc.Option
 
-	home, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Println(err)
-		return
+	if conf.BaseURL != "" {
+		options = append(options, aoc.WithBaseURL(conf.BaseURL))
+	}
+
+	userAgent := conf.UserAgent
+	if userAgent == "" {
+		userAgent = aoc.UserAgent(Version, conf.Contact)
+	}
+	options = append(options, aoc.WithUserAgent(userAgent))
+
+	if verboseFlag {
+		options = append(options, aoc.WithLogger(log.New(os.Stderr, "", 0)))
 	}
 
-	path, err := findConfigInDir(home)
-	if err == nil {
-		c, err := config.Parse(path)
-		if err == nil {
-			config.Merge(conf, c)
+	if conf.RateLimit != "" || conf.RateBurst != 0 {
+		interval := aoc.DefaultRateInterval
+		if conf.RateLimit != "" {
+			d, err := time.ParseDuration(conf.RateLimit)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid rate_limit %q in config, using the default: %v\n", conf.RateLimit, err)
+			} else {
+				interval = d
+			}
+		}
+		burst := conf.RateBurst
+		if burst == 0 {
+			burst = aoc.DefaultRateBurst
 		}
+		options = append(options, aoc.WithRateLimit(interval, burst))
+	}
+
+	if conf.Retries != 0 {
+		// a negative number disables retrying, zero is the same as not set
+		policy := aoc.DefaultRetryPolicy
+		policy.MaxRetries = max(conf.Retries, 0)
+		options = append(options, aoc.WithRetry(policy))
 	}
 
-	path, err = findConfigInProject()
-	if err == nil {
-		c, err := config.Parse(path)
-		if err == nil {
-			config.Merge(conf, c)
+	// fixtures should capture and reproduce the real requests, so the cache is skipped for them
+	switch {
+	case recordFlag != "":
+		options = append(options, aoc.WithTransport(aoc.NewRecordTransport(recordFlag)))
+	case replayFlag != "":
+		options = append(options, aoc.WithTransport(aoc.NewReplayTransport(replayFlag)), aoc.WithRateLimit(0, 0), aoc.WithRetry(aoc.RetryPolicy{}))
+	}
+
+	if !noCacheFlag && recordFlag == "" && replayFlag == "" {
+		if c, err := openCache(); err == nil {
+			options = append(options, aoc.WithCache(c))
 		}
 	}
 
+	return aoc.NewClient(token, options...)
+}
+
+func initConfig() {
+	conf = &config.Config{}
+	configLayers = nil
+	configWarnings = nil
+
+	var errs []error
+
+	for _, path := range globalConfigFiles() {
+		errs = append(errs, mergeConfigFile(path))
+	}
+
+	if path, err := findConfigInProject(); err == nil {
+		errs = append(errs, mergeConfigFile(path))
+	}
+
+	// the environment is between the files and the flags, so CI and containers don't need config files
+	c, err := envConfig()
+	if c != nil {
+		configLayers = append(configLayers, configLayer{env: true, conf: c})
+		config.Merge(conf, c)
+	}
+	errs = append(errs, err)
+
 	if cfgFlag != "" {
 		if _, err := os.Stat(cfgFlag); err == nil {
-			c, err := config.Parse(cfgFlag)
-			if err == nil {
-				config.Merge(conf, c)
-			}
+			errs = append(errs, mergeConfigFile(cfgFlag))
+		} else {
+			errs = append(errs, fmt.Errorf("Failed to read the config file %s: %v", cfgFlag, err))
 		}
 	}
-}
 
-func findConfigInProject() (string, error) {
-	wd, err := os.Getwd()
-	if err != nil {
-		return "", err
+	activeProfile = conf.Profile
+	if profileFlag != "" {
+		activeProfile = profileFlag
+	}
+	if activeProfile != "" {
+		errs = append(errs, conf.ApplyProfile(activeProfile))
 	}
 
-	for i := 0; i < 3; i++ {
-		entries, err := os.ReadDir(wd)
-		if err != nil {
-			return "", fmt.Errorf("Error reading directory: %v", err)
+	// an invalid value only leaves its key out, so it doesn't block the commands which don't need it
+	for i, err := range errs {
+		var verr *config.ValidationError
+		if errors.As(err, &verr) {
+			configWarnings = append(configWarnings, err)
+			errs[i] = nil
 		}
+	}
+	configErr = errors.Join(errs...)
+}
 
-		for _, entry := range entries {
-			if entry.IsDir() {
-				continue
-			}
-			if strings.Contains(entry.Name(), ".aocli") {
-				return filepath.Join(wd, entry.Name()), nil
-			}
-		}
+// checkConfig prints the problems of the values left out of the config and returns the error of loading it
+func checkConfig(cmd *cobra.Command) error {
+	for _, w := range configWarnings {
+		cmd.PrintErrf("%v\nThe invalid values are ignored, check the config with \"aocli config validate\".\n", w)
+	}
+	configWarnings = nil
+	return configErr
+}
 
-		wd = filepath.Dir(wd)
+// configLayer is a config file or the environment which was merged into the config
+type configLayer struct {
+	path string
+	env  bool
+	conf *config.Config
+}
+
+// configLayers are the config files and the environment in the order they were merged, so it can be told where a value comes from
+var configLayers []configLayer
+
+// mergeConfigFile merges the config file into the config and remembers if it contains a session.
+// The invalid values of the file are left out and returned as a *config.ValidationError.
+func mergeConfigFile(path string) error {
+	c, err := config.Parse(path)
+	if err != nil {
+		return fmt.Errorf("Failed to parse the config file %s: %v", path, err)
+	}
+	if c.HasPlaintextSession() {
+		plaintextSessions = append(plaintextSessions, path)
 	}
 
-	return "", fmt.Errorf("No config file found in project")
+	err = config.ValidateFile(path, c)
+	var verr *config.ValidationError
+	if errors.As(err, &verr) {
+		c.RemoveInvalid(verr)
+	}
+	configLayers = append(configLayers, configLayer{path: path, conf: c})
+	config.Merge(conf, c)
+	return err
 }
 
-// findConfigInDir searches for a config file in the given directory
-func findConfigInDir(dir string) (string, error) {
-	entries, err := os.ReadDir(dir)
+// envConfig returns the config set by the environment variables without the invalid values,
+// the problems name the variables instead of the keys
+func envConfig() (*config.Config, error) {
+	c, err := config.FromEnv(os.LookupEnv)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		if strings.Contains(entry.Name(), ".aocli") {
-			return filepath.Join(dir, entry.Name()), nil
+	err = c.Validate()
+	var verr *config.ValidationError
+	if errors.As(err, &verr) {
+		c.RemoveInvalid(verr)
+		for i := range verr.Problems {
+			verr.Problems[i].Key = config.EnvName(verr.Problems[i].Key)
 		}
 	}
+	return c, err
+}
+
+// globalConfigPath returns the global config file or the path where a new one is created
+func globalConfigPath() (string, error) {
+	if files := globalConfigFiles(); len(files) > 0 {
+		return files[len(files)-1], nil
+	}
 
-	return "", errors.New("No config file found in home directory")
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, globalConfigNames[0]), nil
 }
 
-// getSessionToken returns the session token in order from the flag or config
-func getSessionToken() string {
-	if sessionFlag != "" {
-		return sessionFlag
+// readConfigFile parses the config file, a file which doesn't exist yet is an empty config
+func readConfigFile(path string) (*config.Config, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return &config.Config{}, nil
 	}
+	return config.Parse(path)
+}
 
-	if conf.Session != "" {
-		return conf.Session
+// findConfigInProject returns the config file at the root of the project
+func findConfigInProject() (string, error) {
+	_, path, err := findProjectRoot()
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", fmt.Errorf("No config file found in project")
 	}
+	return path, nil
+}
 
-	return ""
+// findConfigInDir searches for a config file with one of the names of a project config in the given directory
+func findConfigInDir(dir string) (string, error) {
+	return findConfigFile(dir, projectConfigNames)
 }
@@ -0,0 +1,469 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitsimi/aocli/internal/aoc"
+	"github.com/mitsimi/aocli/internal/browser"
+	"github.com/mitsimi/aocli/internal/secret"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// sessionEnvVars are the environment variables which are checked for the session token in this order
+var sessionEnvVars = []string{"AOC_SESSION", "AOCLI_SESSION"}
+
+// passphraseEnvVar is the environment variable with the passphrase of the encrypted session file
+const passphraseEnvVar = "AOCLI_PASSPHRASE"
+
+// plaintextSessions are the config files which contain a session in plaintext
+var plaintextSessions []string
+
+// the session is only looked up once per run, so the passphrase isn't asked for repeatedly
+var sessionLookup struct {
+	done   bool
+	token  string
+	origin string
+	err    error
+}
+
+// sessionCmd represents the session command
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Manage the session token used for adventofcode.com",
+	// the session commands have to work without a valid session token
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return checkConfig(cmd)
+	},
+}
+
+var sessionWhichCmd = &cobra.Command{
+	Use:   "which",
+	Short: "Show where the active session token comes from",
+	Long: `Show where the active session token comes from without printing the token.
+The token is looked up in this order:
+  1. the --session flag
+  2. the AOC_SESSION or AOCLI_SESSION environment variable
+  3. the file set with session_file in the config
+  4. the session stored with "aocli session set" in the keyring or the encrypted file
+  5. the file session in the aocli config directory (e.g. ~/.config/aocli/session), unless a profile is used
+  6. the session key in the config or the profile`,
+	Args: cobra.NoArgs,
+	RunE: executeSessionWhich,
+}
+
+var sessionSetCmd = &cobra.Command{
+	Use:   "set [TOKEN]",
+	Short: "Store the session token encrypted",
+	Long: `Store the session token encrypted instead of in plaintext.
+The token is stored in the keyring of the system (the Secret Service on Linux) if one is available.
+Otherwise it is stored in a file in the aocli config directory, encrypted with a passphrase using age.
+The passphrase is asked for when the token is needed or can be set with the AOCLI_PASSPHRASE environment variable.
+Without the argument the token is read from the terminal without echoing it or from standard input.
+Every profile has its own stored session.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: executeSessionSet,
+}
+
+var sessionImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import the session cookie from a browser",
+	Long: `Import the session cookie of adventofcode.com from the cookie database of a browser.
+Firefox stores the cookies in cookies.sqlite, Chromium and Chrome in Cookies, which is encrypted on Linux.
+Without --browser-profile the most recently used browser profile is taken.
+The session is checked with adventofcode.com and stored like with "aocli session set" for the active profile.`,
+	Args: cobra.NoArgs,
+	RunE: executeSessionImport,
+}
+
+func init() {
+	rootCmd.AddCommand(sessionCmd)
+	sessionCmd.AddCommand(sessionWhichCmd, sessionSetCmd, sessionImportCmd)
+
+	sessionSetCmd.Flags().String("storage", "", "where to store the token: keyring or file (default from session_storage in the config, otherwise the keyring if available)")
+
+	sessionImportCmd.Flags().String("browser", "", "browser to import the cookie from: "+strings.Join(browser.Browsers, " or "))
+	sessionImportCmd.Flags().String("browser-profile", "", "path of the browser profile or its cookie database")
+	sessionImportCmd.Flags().String("storage", "", "where to store the token: keyring or file")
+	sessionImportCmd.MarkFlagRequired("browser")
+}
+
+func executeSessionWhich(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	token, origin, err := lookupSessionToken()
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return fmt.Errorf("no session token provided")
+	}
+
+	cmd.Println(origin)
+	return nil
+}
+
+func executeSessionSet(cmd *cobra.Command, args []string) error {
+	storage := conf.SessionStorage
+	if cmd.Flag("storage").Changed {
+		storage, _ = cmd.Flags().GetString("storage")
+	}
+
+	var token string
+	if len(args) == 1 {
+		token = strings.TrimSpace(args[0])
+	} else {
+		t, err := readSecret("Session token: ")
+		if err != nil {
+			return err
+		}
+		token = t
+	}
+	if token == "" {
+		return fmt.Errorf("no session token provided")
+	}
+	cmd.SilenceUsage = true
+
+	return storeSession(cmd, storage, token)
+}
+
+func executeSessionImport(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	name, _ := cmd.Flags().GetString("browser")
+	browserProfile, _ := cmd.Flags().GetString("browser-profile")
+	storage := conf.SessionStorage
+	if cmd.Flag("storage").Changed {
+		storage, _ = cmd.Flags().GetString("storage")
+	}
+
+	// the host of the cookie is known once the client knows the base URL
+	c := newClient("")
+	site, err := url.Parse(c.BaseURL())
+	if err != nil {
+		return err
+	}
+
+	token, err := browser.SessionCookie(name, expandHome(browserProfile), site.Hostname())
+	if err != nil {
+		return err
+	}
+
+	user, err := newClient(token).WhoAmI(cmd.Context())
+	if errors.Is(err, aoc.ErrSessionExpired) {
+		return fmt.Errorf("the session cookie of %s is expired, log in to adventofcode.com in the browser again", name)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to check the session: %v", err)
+	}
+	cmd.Printf("Found the session of %s in %s\n", user.Name, name)
+
+	return storeSession(cmd, storage, token)
+}
+
+// storeSession saves the token in the storage, it falls back to the encrypted file if the keyring isn't available
+func storeSession(cmd *cobra.Command, storage string, token string) error {
+	var store secret.Store
+	switch storage {
+	case "keyring":
+		if !secret.KeyringAvailable() {
+			return fmt.Errorf("no keyring available, use the file storage instead")
+		}
+		store = secret.Keyring{User: secretUser()}
+	case "file":
+		store = sessionFile(true)
+	case "", "auto":
+		if secret.KeyringAvailable() {
+			store = secret.Keyring{User: secretUser()}
+		} else {
+			cmd.Println("No keyring available, the session is stored in an encrypted file instead.")
+			store = sessionFile(true)
+		}
+	default:
+		return fmt.Errorf("unknown session storage: %s (use keyring or file)", storage)
+	}
+
+	if err := store.Set(token); err != nil {
+		return fmt.Errorf("Failed to store the session in the %s: %v", store.Name(), err)
+	}
+	if err := markKeyring(store); err != nil {
+		return err
+	}
+
+	cmd.Printf("Stored the session in the %s\n", store.Name())
+	if conf.Session != "" || conf.SessionFile != "" {
+		cmd.Printf("The session in the %s is no longer needed and can be removed.\n", configOrigin())
+	}
+	return nil
+}
+
+// markKeyring creates the keyring marker if the session was stored in the keyring and removes it otherwise,
+// so the session stored last is the one which is used
+func markKeyring(store secret.Store) error {
+	marker := keyringMarker()
+	if _, ok := store.(secret.Keyring); !ok {
+		if err := os.Remove(marker); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(marker), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(marker, nil, 0o600)
+}
+
+// secretUser is the user under which the session is stored in the keyring
+func secretUser() string {
+	if activeProfile != "" {
+		return activeProfile
+	}
+	return "default"
+}
+
+// profileSessionPath returns the path of a session file of the active profile in the config directory, e.g. session-work.age
+func profileSessionPath(ext string) string {
+	name := "session" + ext
+	if activeProfile != "" {
+		name = "session-" + activeProfile + ext
+	}
+
+	if dir, err := configDir(); err == nil {
+		return filepath.Join(dir, name)
+	}
+	return name
+}
+
+// keyringMarker returns the file which records that the session of the active profile was stored in the keyring,
+// so the keyring is only asked when there is something to find
+func keyringMarker() string {
+	return profileSessionPath(".keyring")
+}
+
+// sessionFile returns the encrypted session file, every profile has its own.
+// If confirm is set a new passphrase has to be entered twice.
+func sessionFile(confirm bool) secret.File {
+	return secret.File{
+		Path: profileSessionPath(".age"),
+		Passphrase: func() (string, error) {
+			return readPassphrase(confirm)
+		},
+	}
+}
+
+// storedSession returns the session stored with "session set" in the keyring or the encrypted file
+func storedSession() (token string, origin string, err error) {
+	// probing the keyring may start a D-Bus service or prompt to unlock it, so it is only asked if the session was stored there
+	_, markerErr := os.Stat(keyringMarker())
+	inKeyring := conf.SessionStorage == "keyring" || (conf.SessionStorage != "file" && markerErr == nil)
+	if inKeyring && secret.KeyringAvailable() {
+		k := secret.Keyring{User: secretUser()}
+		token, err := k.Get()
+		if err == nil && token != "" {
+			return token, k.Name(), nil
+		}
+		if err != nil && !errors.Is(err, secret.ErrNotFound) {
+			return "", "", fmt.Errorf("failed to read the session from the %s: %v", k.Name(), err)
+		}
+	}
+
+	if conf.SessionStorage != "keyring" {
+		f := sessionFile(false)
+		if !f.Exists() {
+			return "", "", nil
+		}
+		token, err := f.Get()
+		if err != nil {
+			return "", "", err
+		}
+		return token, f.Name(), nil
+	}
+
+	return "", "", nil
+}
+
+func readPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(passphraseEnvVar); passphrase != "" {
+		return passphrase, nil
+	}
+	// standard input may be used for something else, e.g. the answer of submit
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("the session is encrypted, set the passphrase with the %s environment variable", passphraseEnvVar)
+	}
+
+	passphrase, err := readSecret("Passphrase for the session: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("the passphrase must not be empty")
+	}
+
+	if confirm {
+		again, err := readSecret("Repeat the passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", fmt.Errorf("the passphrases don't match")
+		}
+	}
+
+	return passphrase, nil
+}
+
+// readSecret asks for a value on the terminal without echoing it.
+// Without a terminal one line of standard input is read, so the value can be piped in.
+func readSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		return strings.TrimSpace(line), nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	value, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(value)), nil
+}
+
+// getSessionToken returns the session token of the first place in the lookup chain which has one
+func getSessionToken() string {
+	token, _, _ := lookupSessionToken()
+	return token
+}
+
+// lookupSessionToken returns the session token together with a description of where it was found.
+// The order is flag, environment variables, configured session file, stored session, default session file and config.
+func lookupSessionToken() (token string, origin string, err error) {
+	if !sessionLookup.done {
+		sessionLookup.token, sessionLookup.origin, sessionLookup.err = findSessionToken()
+		sessionLookup.done = true
+		warnPlaintextSessions()
+	}
+	return sessionLookup.token, sessionLookup.origin, sessionLookup.err
+}
+
+func findSessionToken() (token string, origin string, err error) {
+	if sessionFlag != "" {
+		return sessionFlag, "flag --session", nil
+	}
+
+	for _, name := range sessionEnvVars {
+		if token := strings.TrimSpace(os.Getenv(name)); token != "" {
+			return token, "environment variable " + name, nil
+		}
+	}
+
+	if conf.SessionFile != "" {
+		path := expandHome(conf.SessionFile)
+		token, err := readSessionFile(path)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read the session_file from the config: %v", err)
+		}
+		if token != "" {
+			return token, "file " + path + " (session_file in " + configOrigin() + ")", nil
+		}
+	}
+
+	token, origin, err = storedSession()
+	if err != nil || token != "" {
+		return token, origin, err
+	}
+
+	// the default file can't belong to a profile, so it would shadow the session of the profile
+	if path, err := defaultSessionFile(); err == nil && activeProfile == "" {
+		if token, err := readSessionFile(path); err == nil && token != "" {
+			return token, "file " + path, nil
+		}
+	}
+
+	if conf.Session != "" {
+		return conf.Session, "session key in " + configOrigin(), nil
+	}
+
+	return "", "", nil
+}
+
+// warnPlaintextSessions warns about sessions in plaintext files which may be committed by accident
+func warnPlaintextSessions() {
+	files := plaintextSessions
+	if conf.SessionFile != "" {
+		files = append(files, expandHome(conf.SessionFile))
+	}
+
+	for _, path := range files {
+		if inGitWorkTree(path) {
+			fmt.Fprintf(os.Stderr, "Warning: %s contains the session in plaintext and is inside a git working tree. "+
+				"Add it to .gitignore or store the session encrypted with \"aocli session set\".\n", path)
+		}
+	}
+}
+
+// inGitWorkTree reports if the file is inside a git working tree and not ignored by git
+func inGitWorkTree(path string) bool {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	dir := filepath.Dir(path)
+
+	if err := exec.Command("git", "-C", dir, "rev-parse", "--is-inside-work-tree").Run(); err != nil {
+		return false
+	}
+	// check-ignore exits with 0 if the file is ignored
+	return exec.Command("git", "-C", dir, "check-ignore", "-q", path).Run() != nil
+}
+
+// configOrigin describes where the session settings of the config come from
+func configOrigin() string {
+	if activeProfile != "" {
+		return "profile " + activeProfile
+	}
+	return "config"
+}
+
+// defaultSessionFile returns the path of the session file in the aocli config directory
+func defaultSessionFile() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "session"), nil
+}
+
+// readSessionFile reads the token from the file, surrounding whitespace like a trailing newline is removed
+func readSessionFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// expandHome replaces a leading ~ with the home directory of the user
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
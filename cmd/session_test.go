@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitsimi/aocli/internal/secret"
+)
+
+// newSessionTest prepares the stored sessions: the keyring is in memory and the passphrase of the file is set.
+// No session is in the environment, so the stored one is found.
+func newSessionTest(t *testing.T) (root string, configDir string) {
+	t.Helper()
+	newTestServer(t)
+	secret.MockInit()
+	t.Setenv("AOC_SESSION", "")
+	t.Setenv(passphraseEnvVar, "passphrase")
+	return t.TempDir(), filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "aocli")
+}
+
+func TestKeyringMarker(t *testing.T) {
+	root, dir := newSessionTest(t)
+
+	runAocli(t, root, "session", "set", "--storage", "keyring", "keyring-token")
+	if !exists(dir, "session.keyring") {
+		t.Fatal("the keyring marker wasn't created")
+	}
+	if out := runAocli(t, root, "session", "which"); !strings.Contains(out, "keyring (service aocli, user default)") {
+		t.Errorf("the session isn't taken from the keyring:\n%s", out)
+	}
+
+	// the session stored last wins, so the marker is removed
+	runAocli(t, root, "session", "set", "--storage", "file", "file-token")
+	if exists(dir, "session.keyring") {
+		t.Error("the keyring marker wasn't removed")
+	}
+	if out := runAocli(t, root, "session", "which"); !strings.Contains(out, "encrypted file "+filepath.Join(dir, "session.age")) {
+		t.Errorf("the session isn't taken from the file:\n%s", out)
+	}
+
+	// every profile has its own marker
+	writeFiles(t, root, map[string]string{".aocli.toml": "[profiles.work]\nyear = 2024\n"})
+	runAocli(t, root, "--profile", "work", "session", "set", "--storage", "keyring", "work-token")
+	if !exists(dir, "session-work.keyring") || exists(dir, "session.keyring") {
+		t.Error("the marker of the profile is wrong")
+	}
+	if out := runAocli(t, root, "--profile", "work", "session", "which"); !strings.Contains(out, "user work") {
+		t.Errorf("the session of the profile isn't taken from the keyring:\n%s", out)
+	}
+}
+
+func TestSessionLookupOrder(t *testing.T) {
+	root, dir := newSessionTest(t)
+	sessionFile := filepath.Join(root, "aoc-session")
+	writeFiles(t, root, map[string]string{
+		".aocli.toml": "session = \"config-token\"\nsession_file = \"" + filepath.ToSlash(sessionFile) + "\"\n",
+		"aoc-session": "file-token\n",
+	})
+	writeFiles(t, dir, map[string]string{"session": "default-token\n"})
+	runAocli(t, root, "session", "set", "--storage", "keyring", "keyring-token")
+	t.Setenv("AOC_SESSION", "env-token")
+
+	// after every step its source is removed, so the next one is found
+	steps := []struct {
+		args   []string
+		origin string
+		remove func() error
+	}{
+		{[]string{"--session", "flag-token"}, "flag --session", nil},
+		{nil, "environment variable AOC_SESSION", func() error { return os.Setenv("AOC_SESSION", "") }},
+		{nil, "file " + sessionFile, func() error { return os.WriteFile(sessionFile, nil, 0o600) }},
+		{nil, "keyring (service aocli, user default)", func() error { return os.Remove(filepath.Join(dir, "session.keyring")) }},
+		{nil, "file " + filepath.Join(dir, "session"), func() error { return os.Remove(filepath.Join(dir, "session")) }},
+		{nil, "session key in", nil},
+	}
+	for _, step := range steps {
+		out := runAocli(t, root, append(step.args, "session", "which")...)
+		if !strings.HasPrefix(out, step.origin) {
+			t.Errorf("origin = %q, want %s", out, step.origin)
+		}
+		if step.remove != nil {
+			if err := step.remove(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
@@ -42,22 +42,28 @@ func executeSubmit(cmd *cobra.Command, args []string) error {
 	day := getDay(cmd)
 	cmd.Printf("Submitting answer %s for %d/%d, level %d\n", answer, year, day, level)
 
-	outcome, err := client.SubmitAnswer(aoc.Level(level), year, day, answer)
+	// the arguments are fine, errors from here on are about the site or the session
+	cmd.SilenceUsage = true
+
+	outcome, err := client.SubmitAnswerContext(cmd.Context(), aoc.Level(level), year, day, answer)
 	//outcome, err := aoc.SubmissionIncorrect, nil
 	if err != nil {
 		return err
 	}
+	recordSubmission(cmd, level, year, day, answer, outcome)
 
 	switch outcome {
 	case aoc.SubmissionCorrect:
 		cmd.Println("Your solution is correct! 🎉")
 		if level == 1 {
-			cmd.Println("Use download to get the second part of the puzzle description.")
+			refreshDescription(cmd, year, day)
 		}
 	case aoc.SubmissionIncorrect:
 		cmd.Println("Your solution is incorrect. 😢")
 	case aoc.SubmissionWait:
 		cmd.Println("You have to wait a bit before submitting again.")
+	case aoc.SubmissionOthersAnswer:
+		cmd.Println("Your solution is incorrect, but it is the answer of someone else. Check that the session is the one of your account.")
 	case aoc.SubmissionWrongLevel:
 		cmd.Println("Something went wrong. Please check the level.")
 	case aoc.SubmissionError:
@@ -66,6 +72,33 @@ func executeSubmit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// refreshDescription downloads the description again after the first part was solved, so it contains the second part.
+// Only a description which was downloaded before is updated and no folder is created, otherwise the hint tells how to get it.
+func refreshDescription(cmd *cobra.Command, year, day int) {
+	hint := "Use aocli download -D --update to get the second part of the puzzle description."
+	getPage := func() (*aoc.PuzzlePage, error) {
+		return client.GetPuzzlePageContext(cmd.Context(), year, day)
+	}
+
+	files, err := findPuzzleFiles(year, day, getPage)
+	if err != nil {
+		cmd.Println(hint)
+		return
+	}
+	if _, err := os.Stat(files.description); err != nil {
+		cmd.Println(hint)
+		return
+	}
+
+	w := &puzzleWriter{mode: updateData}
+	if err := saveDescription(w, getPage, files.description); err != nil {
+		cmd.PrintErrf("Failed to update the description: %v\n", err)
+		cmd.Println(hint)
+		return
+	}
+	cmd.Println("Updated the description with the second part of the puzzle.")
+}
+
 // getAnswer returns the answer from the stdin, file or argument
 func getAnswer(cmd *cobra.Command, args []string) (string, error) {
 	answer, err := readStdin()
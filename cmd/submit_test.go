@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSubmitRefreshesDescription(t *testing.T) {
+	newTestServer(t)
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".aocli.toml"), []byte("year = 2024\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	runAocli(t, root, "download", "-d", "1", "-D")
+
+	out := runAocli(t, root, "submit", "-d", "1", "15")
+	if !strings.Contains(out, "Updated the description") {
+		t.Errorf("the description wasn't updated:\n%s", out)
+	}
+	if description := readFile(t, filepath.Join(root, "day01", "description.md")); !strings.Contains(description, "product of all numbers") {
+		t.Errorf("description misses part two:\n%s", description)
+	}
+}
+
+func TestSubmitCreatesNoFolder(t *testing.T) {
+	newTestServer(t)
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".aocli.toml"), []byte("year = 2024\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// without a downloaded description only the hint is printed
+	out := runAocli(t, root, "submit", "-d", "1", "15")
+	if !strings.Contains(out, "aocli download -D --update") {
+		t.Errorf("the hint is missing:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join(root, "day01")); !os.IsNotExist(err) {
+		t.Errorf("submit created the folder of the puzzle: %v", err)
+	}
+}
+
+func TestSubmitHistory(t *testing.T) {
+	newTestServer(t)
+	root := t.TempDir()
+	config := "year = 2024\n\n[profiles.work]\nyear = 2024\n"
+	if err := os.WriteFile(filepath.Join(root, ".aocli.toml"), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	runAocli(t, root, "--profile", "work", "submit", "-d", "1", "14")
+	runAocli(t, root, "--profile", "work", "submit", "-d", "1", "15")
+
+	// the answers are only in the history of the profile they were submitted with
+	if out := runAocli(t, root, "history"); !strings.Contains(out, "No answers were submitted yet.") {
+		t.Errorf("the history without the profile isn't empty:\n%s", out)
+	}
+	out := runAocli(t, root, "--profile", "work", "history", "-d", "1")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "2024/1") || !strings.Contains(lines[0], "14") || !strings.Contains(lines[1], "15") {
+		t.Errorf("history =\n%s", out)
+	}
+	if !strings.Contains(lines[0], "Incorrect answer") || !strings.Contains(lines[1], "Correct answer") {
+		t.Errorf("the outcomes are missing:\n%s", out)
+	}
+	if out := runAocli(t, root, "--profile", "work", "history", "-d", "2"); !strings.Contains(out, "No answers were submitted yet.") {
+		t.Errorf("the filter doesn't skip the other days:\n%s", out)
+	}
+}
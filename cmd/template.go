@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/mitsimi/aocli/internal/template"
+	"github.com/spf13/cobra"
+)
+
+// templateCmd represents the template command
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Manage the templates for new puzzles",
+	Long: `Manage the templates for new puzzles.
+A template is a folder in the templates folder of the project, in the global one (e.g. ~/.config/aocli/templates) or one of the built-in starters.
+A template of the project shadows a global one with the same name, which shadows a built-in one.`,
+	// the templates don't need a session
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkConfig(cmd); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+		return nil
+	},
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all templates",
+	Args:  cobra.NoArgs,
+	RunE:  executeTemplateList,
+}
+
+var templateShowCmd = &cobra.Command{
+	Use:   "show NAME",
+	Short: "Show where a template comes from and its files",
+	Args:  cobra.ExactArgs(1),
+	RunE:  executeTemplateShow,
+}
+
+var templateAddCmd = &cobra.Command{
+	Use:   "add NAME [DIR]",
+	Short: "Add a template to the registry",
+	Long: `Add a template to the registry by copying the folder DIR.
+Without DIR the built-in starter with the name is copied, so it can be changed.
+The template is added to the global templates folder or with --project to the one of the project.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: executeTemplateAdd,
+}
+
+func init() {
+	rootCmd.AddCommand(templateCmd)
+	templateCmd.AddCommand(templateListCmd, templateShowCmd, templateAddCmd)
+
+	templateAddCmd.Flags().Bool("project", false, "add the template to the templates folder of the project")
+}
+
+// templateDirs returns the folders of the template registry, the one of the workspace shadows the global one
+func templateDirs(root string) []template.Dir {
+	dirs := []template.Dir{{Source: template.SourceProject, Path: filepath.Join(root, "templates")}}
+	if dir, err := configDir(); err == nil {
+		dirs = append(dirs, template.Dir{Source: template.SourceGlobal, Path: filepath.Join(dir, "templates")})
+	}
+	return dirs
+}
+
+// templateRoot returns the root of the project or the current folder outside of one
+func templateRoot() (string, error) {
+	if root, _, err := findProjectRoot(); err == nil {
+		return root, nil
+	}
+	return os.Getwd()
+}
+
+func executeTemplateList(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	root, err := templateRoot()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	seen := make(map[string]bool)
+	for _, t := range template.List(templateDirs(root)...) {
+		note := ""
+		if seen[t.Name] {
+			note = "(shadowed)"
+		} else if t.Name == conf.DefaultTemplate {
+			note = "(default)"
+		}
+		seen[t.Name] = true
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", t.Name, t.Source, t.Path, note)
+	}
+	return w.Flush()
+}
+
+func executeTemplateShow(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	root, err := templateRoot()
+	if err != nil {
+		return err
+	}
+
+	t, err := template.Find(args[0], templateDirs(root)...)
+	if err != nil {
+		return err
+	}
+	files, err := t.Files()
+	if err != nil {
+		return err
+	}
+
+	if t.Path != "" {
+		cmd.Printf("%s (%s): %s\n", t.Name, t.Source, t.Path)
+	} else {
+		cmd.Printf("%s (%s)\n", t.Name, t.Source)
+	}
+	for _, file := range files {
+		cmd.Println("  " + file)
+	}
+	return nil
+}
+
+func executeTemplateAdd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := template.ValidateName(name); err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	var src template.Template
+	if len(args) == 2 {
+		info, err := os.Stat(args[1])
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a folder", args[1])
+		}
+		src = template.Template{FS: os.DirFS(args[1])}
+	} else {
+		t, err := template.Builtin(name)
+		if err != nil {
+			return fmt.Errorf("there is no built-in template %s, give the folder of the template", name)
+		}
+		src = t
+	}
+
+	dir := ""
+	if project, _ := cmd.Flags().GetBool("project"); project {
+		root, err := templateRoot()
+		if err != nil {
+			return err
+		}
+		dir = templateDirs(root)[0].Path
+	} else {
+		global, err := configDir()
+		if err != nil {
+			return err
+		}
+		dir = filepath.Join(global, "templates")
+	}
+
+	dst := filepath.Join(dir, name)
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("the template %s already exists in %s", name, dst)
+	}
+	if err := template.WriteFiles(src.FS, dst); err != nil {
+		return fmt.Errorf("Failed to write the template: %v", err)
+	}
+
+	cmd.Printf("Added the template %s in %s\n", name, dst)
+	return nil
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mitsimi/aocli/internal/aoc"
+	"github.com/spf13/cobra"
+)
+
+// whoamiCmd represents the whoami command
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show the account of the session and check if the session is valid",
+	Args:  cobra.NoArgs,
+	RunE:  executeWhoami,
+}
+
+func init() {
+	rootCmd.AddCommand(whoamiCmd)
+}
+
+func executeWhoami(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	user, err := client.WhoAmI(cmd.Context())
+	if errors.Is(err, aoc.ErrSessionExpired) {
+		_, origin, _ := lookupSessionToken()
+		return fmt.Errorf("%v\nThe session is taken from %s.", err, origin)
+	}
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("Logged in as %s\n", user.Name)
+	if user.ID != 0 {
+		cmd.Printf("User ID: %d\n", user.ID)
+	}
+	if user.Supporter {
+		cmd.Println("Supporter: yes (AoC++)")
+	} else {
+		cmd.Println("Supporter: no")
+	}
+	cmd.Println("The session is valid.")
+	return nil
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitsimi/aocli/internal/aoc"
+	"github.com/mitsimi/aocli/internal/aoctest"
+)
+
+func TestWhoami(t *testing.T) {
+	s := newTestServer(t)
+	s.User = aoctest.User{ID: 4711, Name: "Santa", Supporter: true}
+
+	out := runAocli(t, t.TempDir(), "whoami")
+	for _, line := range []string{"Logged in as Santa", "User ID: 4711", "Supporter: yes (AoC++)", "The session is valid."} {
+		if !strings.Contains(out, line) {
+			t.Errorf("%q is missing:\n%s", line, out)
+		}
+	}
+}
+
+func TestWhoamiExpiredSession(t *testing.T) {
+	newTestServer(t)
+	t.Setenv("AOC_SESSION", "expired-session")
+
+	out, err := runAocliErr(t, t.TempDir(), "whoami")
+	if err == nil || !strings.Contains(err.Error(), aoc.ErrSessionExpired.Error()) {
+		t.Fatalf("err = %v, want the expired session\n%s", err, out)
+	}
+	if !strings.Contains(err.Error(), "The session is taken from environment variable AOC_SESSION.") {
+		t.Errorf("the origin of the session is missing: %v", err)
+	}
+}
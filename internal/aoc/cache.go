@@ -0,0 +1,100 @@
+package aoc
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/mitsimi/aocli/internal/cache"
+)
+
+// Resources of a puzzle which are stored in the cache
+const (
+	ResourcePage  = "page"
+	ResourceInput = "input"
+)
+
+// accountID derives an identifier for the account from the session token, so the token itself is never written to disk.
+// Responses of another site than Advent of Code are kept apart from the real ones.
+func accountID(baseURL, token string) string {
+	if baseURL != DefaultBaseURL {
+		token = baseURL + "\n" + token
+	} else if token == "" {
+		return "anonymous"
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Account returns the identifier under which responses for the session of the client are cached
+func (c *Client) Account() string {
+	return c.account
+}
+
+// IncompletePageTTL is how long a page whose puzzle isn't solved completely is taken from the cache.
+// Part two unlocks when part one is solved, which may happen on the site, so the page is requested again after a while.
+// A correct answer submitted with the client removes the page from the cache right away.
+const IncompletePageTTL = 5 * time.Minute
+
+// IsPageComplete reports if both parts of the puzzle on the page are solved.
+// A complete page won't change anymore, every other page might unlock part two at any time.
+func IsPageComplete(page []byte) bool {
+	return bytes.Contains(page, []byte("Both parts of this puzzle are complete"))
+}
+
+// isPageValid reports if the cached page can be used, complete pages are valid forever
+func isPageValid(page []byte, age time.Duration) bool {
+	return IsPageComplete(page) || age < IncompletePageTTL
+}
+
+// fetchCached returns the resource from the cache if it is still valid for its content and age,
+// otherwise the url is requested and the response is stored in the cache
+func (c *Client) fetchCached(ctx context.Context, year, day int, resource, url string, valid func(data []byte, age time.Duration) bool) ([]byte, error) {
+	key := cache.Key{Account: c.account, Year: year, Day: day, Resource: resource}
+	if c.cache != nil {
+		if data, modTime, ok := c.cache.Get(key); ok && valid(data, time.Since(modTime)) {
+			return data, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := c.Request(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	// a login prompt must not end up in the cache
+	if err := c.checkSession(data); err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		// a failing cache must not fail the request, we just fetch it again next time
+		c.cache.Put(key, data)
+	}
+
+	return data, nil
+}
+
+// InvalidatePuzzle removes the cached page of the puzzle so it is requested again
+func (c *Client) InvalidatePuzzle(year, day int) error {
+	if c.cache == nil {
+		return nil
+	}
+	return c.cache.Delete(cache.Key{Account: c.account, Year: year, Day: day, Resource: ResourcePage})
+}
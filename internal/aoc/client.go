@@ -7,17 +7,41 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"strings"
 	"time"
+
+	"github.com/mitsimi/aocli/internal/cache"
 )
 
 type Client struct {
 	http.Client
+
+	baseURL    string
+	account    string
+	hasSession bool
+	cache      *cache.Cache
+	limiter    *RateLimiter
+	logger     *log.Logger
+	userAgent  string
+	retry      RetryPolicy
 }
 
 // NewClient initializes a new client with a base URL and session token in a jar
 func NewClient(token string, options ...Option) *Client {
-	client := &Client{}
+	client := &Client{
+		baseURL:   DefaultBaseURL,
+		limiter:   NewRateLimiter(DefaultRateInterval, DefaultRateBurst),
+		userAgent: UserAgent("", ""),
+		retry:     DefaultRetryPolicy,
+	}
+
+	for _, opt := range options {
+		opt(client)
+	}
 
+	// the account and cookie are set after the options, because they belong to the configured base URL
+	client.account = accountID(client.baseURL, token)
+	client.hasSession = token != ""
 	if token != "" {
 		jar, err := cookiejar.New(nil)
 		if err != nil {
@@ -29,7 +53,7 @@ func NewClient(token string, options ...Option) *Client {
 			Path:  "/",
 		}
 
-		u, err := url.Parse(BaseURL)
+		u, err := url.Parse(client.baseURL)
 		if err != nil {
 			log.Fatalf("Failed to parse BaseURL: %v", err)
 		}
@@ -39,9 +63,8 @@ func NewClient(token string, options ...Option) *Client {
 		client.Jar = jar
 	}
 
-	for _, opt := range options {
-		opt(client)
-	}
+	// wrap the transport last, so the user agent is also set for a custom transport
+	client.Transport = &userAgentTransport{Transport: client.Transport, UserAgent: client.userAgent}
 
 	return client
 }
@@ -79,7 +102,7 @@ func (e RequestError) Error() string {
 
 func (c *Client) RequestData(req *http.Request) ([]byte, error) {
 	// Perform the request
-	resp, err := c.Client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %v", err)
 	}
@@ -87,7 +110,7 @@ func (c *Client) RequestData(req *http.Request) ([]byte, error) {
 
 	// Check for non-200 status codes
 	if resp.StatusCode != http.StatusOK {
-		return nil, RequestError{resp.StatusCode, req.RemoteAddr, nil}
+		return nil, c.statusError(req, resp)
 	}
 
 	// Read the response body
@@ -101,19 +124,80 @@ func (c *Client) RequestData(req *http.Request) ([]byte, error) {
 
 func (c *Client) Request(req *http.Request) (*http.Response, error) {
 	// Perform the request
-	resp, err := c.Client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %v", err)
 	}
 
 	// Check for non-200 status codes
 	if resp.StatusCode != http.StatusOK {
-		return nil, RequestError{resp.StatusCode, fmt.Sprintf("%s%s", req.Host, req.URL.Path), nil}
+		defer resp.Body.Close()
+		return nil, c.statusError(req, resp)
 	}
 
 	return resp, nil
 }
 
+// statusError returns the error for a response with an unexpected status code.
+// If the site asks to log in, the reason is the missing or expired session.
+func (c *Client) statusError(req *http.Request, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if isLoginPrompt(body) {
+		if c.hasSession {
+			return ErrSessionExpired
+		}
+		return RequestError{resp.StatusCode, req.URL.String(), errLoginRequired}
+	}
+	return RequestError{resp.StatusCode, req.URL.String(), nil}
+}
+
+// do sends the request as soon as the rate limiter allows it and retries it after transient failures
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	for retry := 0; ; retry++ {
+		if err := c.wait(req); err != nil {
+			return nil, err
+		}
+
+		resp, err := c.Client.Do(req)
+
+		delay, ok := c.retry.delay(retry, req, resp, err)
+		if !ok {
+			return resp, err
+		}
+
+		if err != nil {
+			c.logf("Retrying %s %s in %s: %v", req.Method, req.URL, delay.Round(time.Millisecond), err)
+		} else {
+			c.logf("Retrying %s %s in %s: %s", req.Method, req.URL, delay.Round(time.Millisecond), resp.Status)
+			drain(resp)
+		}
+
+		if err := sleepContext(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// wait blocks until the rate limiter allows the request
+func (c *Client) wait(req *http.Request) error {
+	if c.limiter == nil {
+		return nil
+	}
+
+	delay := c.limiter.reserve()
+	if delay > 0 {
+		c.logf("Rate limit: waiting %s before %s %s", delay.Round(time.Millisecond), req.Method, req.URL)
+	}
+	return c.limiter.sleep(req.Context(), delay)
+}
+
+// logf writes to the logger of the client if one is set
+func (c *Client) logf(format string, v ...any) {
+	if c.logger != nil {
+		c.logger.Printf(format, v...)
+	}
+}
+
 type Option func(c *Client)
 
 // WithTimeout sets the timeout duration for the client
@@ -130,6 +214,60 @@ func WithTransport(transport http.RoundTripper) Option {
 	}
 }
 
+// WithBaseURL sets the address of the site, e.g. to talk to a local stand-in of Advent of Code
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithCache sets the cache for responses which don't have to be requested again
+func WithCache(cache *cache.Cache) Option {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// WithRateLimit limits the client to one request per interval with bursts of up to burst requests.
+// An interval of zero disables the rate limiting.
+func WithRateLimit(interval time.Duration, burst int) Option {
+	return func(c *Client) {
+		if interval <= 0 {
+			c.limiter = nil
+			return
+		}
+		c.limiter = NewRateLimiter(interval, burst)
+	}
+}
+
+// WithRateLimiter sets a rate limiter which may be shared with other clients
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *Client) {
+		c.limiter = limiter
+	}
+}
+
+// WithLogger sets a logger for verbose output about what the client is doing
+func WithLogger(logger *log.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithRetry sets the policy for retrying requests after transient failures
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithUserAgent sets the User-Agent header which is sent with every request
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
 // WithRedirectPolicy sets a custom redirecct policy for the client
 func WithRedirectPolicy(f func(req *http.Request, via []*http.Request) error) Option {
 	return func(c *Client) {
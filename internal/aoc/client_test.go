@@ -0,0 +1,303 @@
+package aoc_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitsimi/aocli/internal/aoc"
+	"github.com/mitsimi/aocli/internal/aoctest"
+	"github.com/mitsimi/aocli/internal/cache"
+)
+
+const session = "test-session"
+
+// newServer starts a fake site with day 1 of 2024 and returns it with a client logged in to it
+func newServer(t *testing.T, options ...aoc.Option) (*aoctest.Server, *aoc.Client) {
+	t.Helper()
+	s := aoctest.NewServer(session)
+	t.Cleanup(s.Close)
+	s.AddPuzzle(aoctest.NewPuzzle(2024, 1))
+	return s, s.NewClient(options...)
+}
+
+// count returns how often the server received the request
+func count(s *aoctest.Server, request string) int {
+	n := 0
+	for _, r := range s.Requests() {
+		if r == request {
+			n++
+		}
+	}
+	return n
+}
+
+func TestGetPuzzlePage(t *testing.T) {
+	_, client := newServer(t)
+
+	page, err := client.GetPuzzlePage(2024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Title != "Puzzle 1" {
+		t.Errorf("title = %q, want %q", page.Title, "Puzzle 1")
+	}
+	if page.Example() != "1\n2\n3\n" {
+		t.Errorf("example = %q, want %q", page.Example(), "1\n2\n3\n")
+	}
+	if page.PartTwoUnlocked || page.Complete || len(page.Answers) != 0 {
+		t.Errorf("unsolved page has part two %v, complete %v, answers %v", page.PartTwoUnlocked, page.Complete, page.Answers)
+	}
+
+	md, err := page.Markdown()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md, "What is the sum of all numbers in your list?") {
+		t.Errorf("markdown misses the question:\n%s", md)
+	}
+}
+
+func TestGetPuzzlePageSolved(t *testing.T) {
+	s, client := newServer(t)
+	s.Puzzle(2024, 1).Solved = 2
+
+	page, err := client.GetPuzzlePage(2024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !page.PartTwoUnlocked || !page.Complete {
+		t.Errorf("solved page has part two %v, complete %v", page.PartTwoUnlocked, page.Complete)
+	}
+	if !slices.Equal(page.Answers, []string{"15", "120"}) {
+		t.Errorf("answers = %v, want [15 120]", page.Answers)
+	}
+}
+
+func TestGetInput(t *testing.T) {
+	_, client := newServer(t)
+
+	input, err := client.GetInput(2024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if input != "4\n5\n6\n" {
+		t.Errorf("input = %q, want %q", input, "4\n5\n6\n")
+	}
+}
+
+func TestGetUnknownPuzzle(t *testing.T) {
+	_, client := newServer(t)
+
+	_, err := client.GetPuzzlePage(2024, 2)
+	var reqErr aoc.RequestError
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != 404 {
+		t.Errorf("err = %v, want a RequestError with status 404", err)
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	s, client := newServer(t, aoc.WithCache(cache.New(dir)))
+
+	for range 2 {
+		if _, err := client.GetInput(2024, 1); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.GetPuzzlePage(2024, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := count(s, "GET /2024/day/1/input"); n != 1 {
+		t.Errorf("input requested %d times, want 1", n)
+	}
+	if n := count(s, "GET /2024/day/1"); n != 1 {
+		t.Errorf("unsolved page requested %d times, want 1", n)
+	}
+
+	// part two can unlock on the site, so an old unsolved page isn't taken from the cache
+	pages, _ := filepath.Glob(filepath.Join(dir, "*", "2024", "01", aoc.ResourcePage))
+	if len(pages) != 1 {
+		t.Fatalf("cached pages = %v", pages)
+	}
+	old := time.Now().Add(-aoc.IncompletePageTTL)
+	if err := os.Chtimes(pages[0], old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetPuzzlePage(2024, 1); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(s, "GET /2024/day/1"); n != 2 {
+		t.Errorf("expired page requested %d times, want 2", n)
+	}
+
+	s.Puzzle(2024, 1).Solved = 2
+	if err := os.Chtimes(pages[0], old, old); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if _, err := client.GetPuzzlePage(2024, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := count(s, "GET /2024/day/1"); n != 3 {
+		t.Errorf("page requested %d times, want 3 because the complete page is cached", n)
+	}
+}
+
+func TestCacheInvalidatedByCorrectAnswer(t *testing.T) {
+	c := cache.New(t.TempDir())
+	_, client := newServer(t, aoc.WithCache(c))
+	key := cache.Key{Account: client.Account(), Year: 2024, Day: 1, Resource: aoc.ResourcePage}
+
+	if _, err := client.GetPuzzlePage(2024, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := c.Get(key); !ok {
+		t.Fatal("the page isn't cached")
+	}
+
+	if _, err := client.SubmitAnswer(aoc.LevelOne, 2024, 1, "15"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := c.Get(key); ok {
+		t.Error("the page is still cached after the correct answer")
+	}
+}
+
+func TestSubmitAnswer(t *testing.T) {
+	tests := []struct {
+		name    string
+		outcome aoctest.Outcome
+		want    aoc.SubmissionOutcome
+	}{
+		{"correct", aoctest.Correct(), aoc.SubmissionCorrect},
+		{"incorrect", aoctest.Incorrect(), aoc.SubmissionIncorrect},
+		{"too high", aoctest.TooHigh(), aoc.SubmissionIncorrect},
+		{"too low", aoctest.TooLow(), aoc.SubmissionIncorrect},
+		{"wait", aoctest.Wait(90 * time.Second), aoc.SubmissionWait},
+		{"wrong level", aoctest.WrongLevel(), aoc.SubmissionWrongLevel},
+		{"others answer", aoctest.OthersAnswer(), aoc.SubmissionOthersAnswer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, client := newServer(t)
+			s.QueueOutcome(2024, 1, tt.outcome)
+
+			got, err := client.SubmitAnswer(aoc.LevelOne, 2024, 1, "42")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("outcome = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubmitAnswerChecked(t *testing.T) {
+	s, client := newServer(t)
+
+	steps := []struct {
+		level  aoc.Level
+		answer string
+		want   aoc.SubmissionOutcome
+	}{
+		{aoc.LevelOne, "14", aoc.SubmissionIncorrect},
+		{aoc.LevelOne, "15", aoc.SubmissionCorrect},
+		{aoc.LevelOne, "15", aoc.SubmissionWrongLevel},
+		{aoc.LevelTwo, "120", aoc.SubmissionCorrect},
+	}
+	for _, step := range steps {
+		got, err := client.SubmitAnswer(step.level, 2024, 1, step.answer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != step.want {
+			t.Errorf("level %d answer %s: outcome = %v, want %v", step.level, step.answer, got, step.want)
+		}
+	}
+
+	submissions := s.Submissions()
+	if len(submissions) != len(steps) || submissions[3].Answer != "120" || submissions[3].Level != 2 {
+		t.Errorf("submissions = %+v", submissions)
+	}
+	if !s.Puzzle(2024, 1).Complete() {
+		t.Error("the puzzle isn't complete after both answers")
+	}
+}
+
+func TestSessionExpired(t *testing.T) {
+	s, _ := newServer(t)
+	client := aoc.NewClient("expired", aoc.WithBaseURL(s.URL), aoc.WithRateLimit(0, 0), aoc.WithRetry(aoc.RetryPolicy{}))
+	ctx := context.Background()
+
+	calls := map[string]func() error{
+		"page": func() error {
+			_, err := client.GetPuzzlePage(2024, 1)
+			return err
+		},
+		"input": func() error {
+			_, err := client.GetInput(2024, 1)
+			return err
+		},
+		"submit": func() error {
+			_, err := client.SubmitAnswer(aoc.LevelOne, 2024, 1, "15")
+			return err
+		},
+		"whoami": func() error {
+			_, err := client.WhoAmI(ctx)
+			return err
+		},
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, aoc.ErrSessionExpired) {
+			t.Errorf("%s: err = %v, want ErrSessionExpired", name, err)
+		}
+	}
+}
+
+func TestWithoutSession(t *testing.T) {
+	s, _ := newServer(t)
+	client := aoc.NewClient("", aoc.WithBaseURL(s.URL), aoc.WithRateLimit(0, 0), aoc.WithRetry(aoc.RetryPolicy{}))
+
+	// without a session the site doesn't know the user, so it isn't expired
+	_, err := client.GetInput(2024, 1)
+	if err == nil || errors.Is(err, aoc.ErrSessionExpired) {
+		t.Errorf("err = %v, want a request error asking for a session", err)
+	}
+	if _, err := client.WhoAmI(context.Background()); err == nil || errors.Is(err, aoc.ErrSessionExpired) {
+		t.Errorf("whoami: err = %v, want an error asking for a session", err)
+	}
+}
+
+func TestWhoAmI(t *testing.T) {
+	tests := []struct {
+		name string
+		user aoctest.User
+	}{
+		{"supporter", aoctest.User{ID: 4711, Name: "Santa", Supporter: true}},
+		{"no supporter", aoctest.User{ID: 1, Name: "aocli tester"}},
+		// the name is escaped in the page
+		{"escaped name", aoctest.User{ID: 2, Name: "Rudolph & <Co>"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, client := newServer(t)
+			s.User = tt.user
+
+			user, err := client.WhoAmI(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user.ID != tt.user.ID || user.Name != tt.user.Name || user.Supporter != tt.user.Supporter {
+				t.Errorf("user = %+v, want %+v", *user, tt.user)
+			}
+		})
+	}
+}
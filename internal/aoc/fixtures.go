@@ -0,0 +1,256 @@
+package aoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// redacted replaces the values of headers which would leak the session
+const redacted = "REDACTED"
+
+var sensitiveHeaders = []string{"Cookie", "Set-Cookie", "Authorization"}
+
+// redactedInput replaces the puzzle inputs, they are personal and must not be shared
+const redactedInput = redacted + ": the puzzle input isn't recorded\n"
+
+// Fixture is a recorded request together with the response of the site
+type Fixture struct {
+	Request  FixtureRequest  `json:"request"`
+	Response FixtureResponse `json:"response"`
+}
+
+type FixtureRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type FixtureResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// fixtureKey identifies the request of a fixture independent of the host, so it can be replayed against any base URL
+func fixtureKey(method string, rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return method + " " + rawURL
+	}
+	return method + " " + u.RequestURI()
+}
+
+// redact returns a copy of the header without the values of the session and the contact in the user agent
+func redact(header http.Header) http.Header {
+	header = header.Clone()
+	for _, name := range sensitiveHeaders {
+		if _, ok := header[name]; ok {
+			header[name] = []string{redacted}
+		}
+	}
+	if ua := header.Get("User-Agent"); ua != "" {
+		header.Set("User-Agent", redactUserAgent(ua))
+	}
+	return header
+}
+
+// redactUserAgent keeps the name and version of aocli for the bug report, but not the contact which is usually an email address.
+// A custom user agent may contain anything, so it is redacted completely.
+func redactUserAgent(ua string) string {
+	if !strings.HasPrefix(ua, userAgentName) {
+		return redacted
+	}
+	tool, _, _ := strings.Cut(ua, " by ")
+	return tool
+}
+
+// redactBody returns the body of the response without the puzzle input, the pages are kept as they are needed to reproduce a bug
+func redactBody(req *http.Request, body []byte) string {
+	if req.Method == http.MethodGet && strings.HasSuffix(req.URL.Path, "/input") {
+		return redactedInput
+	}
+	return string(body)
+}
+
+// RecordTransport saves every request and response as fixture in a directory, with the session and the puzzle inputs redacted.
+// The fixtures can be attached to bug reports and served again by a ReplayTransport.
+type RecordTransport struct {
+	Dir       string
+	Transport http.RoundTripper
+
+	mu sync.Mutex
+	n  int
+}
+
+func NewRecordTransport(dir string) *RecordTransport {
+	return &RecordTransport{
+		Dir:       dir,
+		Transport: http.DefaultTransport,
+	}
+}
+
+func (t *RecordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := t.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	fixture := Fixture{
+		Request: FixtureRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: redact(req.Header),
+			Body:   string(reqBody),
+		},
+		Response: FixtureResponse{
+			StatusCode: resp.StatusCode,
+			Header:     redact(resp.Header),
+			Body:       redactBody(req, respBody),
+		},
+	}
+
+	if err := t.save(fixture); err != nil {
+		return nil, fmt.Errorf("failed to record fixture: %v", err)
+	}
+
+	return resp, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// save writes the fixture into the next numbered file, so the order of the requests is kept
+func (t *RecordTransport) save(fixture Fixture) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		return err
+	}
+
+	// continue the numbering of an earlier recording instead of overwriting it
+	if t.n == 0 {
+		existing, _ := filepath.Glob(filepath.Join(t.Dir, "*.json"))
+		t.n = len(existing)
+	}
+	t.n++
+
+	key := fixtureKey(fixture.Request.Method, fixture.Request.URL)
+	name := fmt.Sprintf("%03d-%s.json", t.n, strings.Trim(unsafeFileChars.ReplaceAllString(key, "-"), "-"))
+
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(t.Dir, name), data, 0o644)
+}
+
+// ReplayTransport answers requests with the fixtures recorded by a RecordTransport instead of asking the site.
+// Fixtures for the same request are served in the order they were recorded, the last one is repeated.
+type ReplayTransport struct {
+	Dir string
+
+	mu       sync.Mutex
+	fixtures map[string][]Fixture
+}
+
+func NewReplayTransport(dir string) *ReplayTransport {
+	return &ReplayTransport{Dir: dir}
+}
+
+// load reads all fixtures of the directory in the order they were recorded
+func (t *ReplayTransport) load() error {
+	files, err := filepath.Glob(filepath.Join(t.Dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no fixtures found in %s", t.Dir)
+	}
+	sort.Strings(files)
+
+	t.fixtures = make(map[string][]Fixture)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		var fixture Fixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return fmt.Errorf("failed to parse fixture %s: %v", file, err)
+		}
+
+		key := fixtureKey(fixture.Request.Method, fixture.Request.URL)
+		t.fixtures[key] = append(t.fixtures[key], fixture)
+	}
+
+	return nil
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.fixtures == nil {
+		if err := t.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	key := fixtureKey(req.Method, req.URL.String())
+	recorded := t.fixtures[key]
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("no recorded response for %s", key)
+	}
+
+	fixture := recorded[0]
+	if len(recorded) > 1 {
+		t.fixtures[key] = recorded[1:]
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.Response.StatusCode, http.StatusText(fixture.Response.StatusCode)),
+		StatusCode:    fixture.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        fixture.Response.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(fixture.Response.Body)),
+		ContentLength: int64(len(fixture.Response.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,57 @@
+package aoc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedactUserAgent(t *testing.T) {
+	tests := map[string]string{
+		UserAgent("v1.2.0", "santa@northpole.example"): "github.com/mitsimi/aocli v1.2.0",
+		UserAgent("", "santa@northpole.example"):       "github.com/mitsimi/aocli",
+		UserAgent("v1.2.0", ""):                        "github.com/mitsimi/aocli v1.2.0",
+		"my-bot/1.0 (santa@northpole.example)":         redacted,
+	}
+	for ua, want := range tests {
+		if got := redactUserAgent(ua); got != want {
+			t.Errorf("redactUserAgent(%q) = %q, want %q", ua, got, want)
+		}
+	}
+}
+
+func TestRecordRedacts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "new-secret"})
+		w.Write([]byte("4\n5\n6\n"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	client := NewClient("secret", WithBaseURL(server.URL), WithRateLimit(0, 0),
+		WithUserAgent(UserAgent("v1.2.0", "santa@northpole.example")), WithTransport(NewRecordTransport(dir)))
+	// only the fixture is redacted, the client gets the input
+	if input, err := client.GetInput(2024, 1); err != nil || input != "4\n5\n6\n" {
+		t.Fatalf("GetInput() = %q, %v", input, err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("recorded %d fixtures, want 1", len(files))
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leak := range []string{"secret", "santa@northpole.example", `4\n5\n6`} {
+		if strings.Contains(string(data), leak) {
+			t.Errorf("the fixture contains %q:\n%s", leak, data)
+		}
+	}
+	if !strings.Contains(string(data), "github.com/mitsimi/aocli v1.2.0") {
+		t.Errorf("the fixture misses the version of aocli:\n%s", data)
+	}
+}
@@ -0,0 +1,115 @@
+package aoc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// PuzzlePage holds everything parsed from the page of a puzzle
+type PuzzlePage struct {
+	Year  int
+	Day   int
+	Title string
+	// URL is the address the page was requested from
+	URL string
+
+	// Description is the content of the <main> tag with all unlocked parts
+	Description HTMLContent
+	// Examples are the code blocks introduced as example in the order they appear on the page
+	Examples []string
+	// Answers are the accepted answers, one for each solved part
+	Answers []string
+
+	// PartTwoUnlocked is true if the description of part two is shown
+	PartTwoUnlocked bool
+	// Complete is true if both parts are solved and the page won't change anymore
+	Complete bool
+}
+
+// Example returns the first example of the puzzle or an empty string if there is none
+func (p *PuzzlePage) Example() string {
+	if len(p.Examples) == 0 {
+		return ""
+	}
+	return p.Examples[0]
+}
+
+// Markdown converts the description to markdown, links are resolved relative to the page
+func (p *PuzzlePage) Markdown() (Markdown, error) {
+	if p.URL == "" {
+		return p.Description.ToMarkdown(p.Year)
+	}
+	return p.Description.toMarkdown(p.URL[:strings.LastIndex(p.URL, "/")+1])
+}
+
+// GetPuzzlePage fetches the page of the puzzle once and parses all of its content
+func (c *Client) GetPuzzlePage(year, day int) (*PuzzlePage, error) {
+	return c.GetPuzzlePageContext(context.Background(), year, day)
+}
+
+// GetPuzzlePageContext is like GetPuzzlePage but the request is canceled when the context is done
+func (c *Client) GetPuzzlePageContext(ctx context.Context, year, day int) (*PuzzlePage, error) {
+	// Get site content
+	data, err := c.fetchCached(ctx, year, day, ResourcePage, c.DayURL(year, day), isPageValid)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := ParsePuzzlePage(year, day, data)
+	if err != nil {
+		return nil, err
+	}
+	page.URL = c.DayURL(year, day)
+
+	return page, nil
+}
+
+// ParsePuzzlePage parses the html of a puzzle page
+func ParsePuzzlePage(year, day int, data []byte) (*PuzzlePage, error) {
+	// Parse the HTML
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse HTML: %v", err)
+	}
+
+	// Extract the <main> tag
+	mainContent, _ := doc.Find("main").Html()
+	if mainContent == "" {
+		return nil, fmt.Errorf("No <main> tag found")
+	}
+
+	page := &PuzzlePage{
+		Year:        year,
+		Day:         day,
+		Title:       parseTitle(doc),
+		Description: HTMLContent(mainContent),
+		Examples:    parseExamples(doc),
+		Complete:    IsPageComplete(data),
+	}
+
+	articles := doc.Find("main > article.day-desc")
+	page.PartTwoUnlocked = articles.Length() > 1
+
+	// every solved part is followed by a paragraph with the accepted answer
+	doc.Find("main > p").Each(func(i int, s *goquery.Selection) {
+		if strings.HasPrefix(s.Text(), "Your puzzle answer was") {
+			page.Answers = append(page.Answers, s.Find("code").First().Text())
+		}
+	})
+
+	return page, nil
+}
+
+// parseTitle extracts the title from the heading in the form "--- Day 1: Title ---"
+func parseTitle(doc *goquery.Document) string {
+	heading := doc.Find("main > article.day-desc h2").First().Text()
+	heading = strings.Trim(heading, "- ")
+	if _, title, ok := strings.Cut(heading, ": "); ok {
+		return title
+	}
+	return heading
+}
@@ -1,9 +1,11 @@
 package aoc
 
 import (
+	"bytes"
+	"context"
 	"fmt"
-	"net/http"
 	"strings"
+	"time"
 
 	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
 	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/base"
@@ -14,72 +16,48 @@ import (
 
 // getDescription fetches the and parses the html content
 func (c *Client) GetDescription(year, day int) (HTMLContent, error) {
-	// Create the request
-	req, err := http.NewRequest("GET", DayURL(year, day), nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %v", err)
-	}
+	return c.GetDescriptionContext(context.Background(), year, day)
+}
 
-	// Get site content
-	resp, err := c.Request(req)
+// GetDescriptionContext is like GetDescription but the request is canceled when the context is done
+func (c *Client) GetDescriptionContext(ctx context.Context, year, day int) (HTMLContent, error) {
+	page, err := c.GetPuzzlePageContext(ctx, year, day)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
-
-	// Parse the HTML
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("Failed to parse HTML: %v", err)
-	}
-
-	// Extract the <main> tag
-	mainContent, _ := doc.Find("main").Html()
-	if mainContent == "" {
-		return "", fmt.Errorf("No <main> tag found")
-	}
 
-	return HTMLContent(mainContent), nil
+	return page.Description, nil
 }
 
+// GetExample returns the first example of the puzzle
 func (c *Client) GetExample(year, day int) (string, error) {
-	// Create the request
-	req, err := http.NewRequest("GET", DayURL(year, day), nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
+	return c.GetExampleContext(context.Background(), year, day)
+}
 
-	// Get site content
-	resp, err := c.Request(req)
+// GetExampleContext is like GetExample but the request is canceled when the context is done
+func (c *Client) GetExampleContext(ctx context.Context, year, day int) (string, error) {
+	page, err := c.GetPuzzlePageContext(ctx, year, day)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
 
-	// Parse the HTML
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("Failed to parse HTML: %v", err)
-	}
-
-	return parseExample(doc), nil
+	return page.Example(), nil
 }
 
+// GetInput returns the puzzle input of the account
 func (c *Client) GetInput(year, day int) (string, error) {
-	// Create the request
-	req, err := http.NewRequest("GET", InputURL(year, day), nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
+	return c.GetInputContext(context.Background(), year, day)
+}
 
-	// Get site content
-	resp, err := c.Request(req)
+// GetInputContext is like GetInput but the request is canceled when the context is done
+func (c *Client) GetInputContext(ctx context.Context, year, day int) (string, error) {
+	// Get site content, the input never changes so a cached one is always valid
+	input, err := c.fetchCached(ctx, year, day, ResourceInput, c.InputURL(year, day), func([]byte, time.Duration) bool { return true })
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(input))
 	if err != nil {
 		return "", fmt.Errorf("Failed to parse HTML: %v", err)
 	}
@@ -93,7 +71,8 @@ func (c *Client) GetInput(year, day int) (string, error) {
 }
 
 // parses each code block after a p element if it contains the word "example"
-func parseExample(doc *goquery.Document) string {
+func parseExamples(doc *goquery.Document) []string {
+	var examples []string
 	dupe := make(map[string]struct{})
 	// Find the desired <code> tags
 	doc.Find("p").Each(func(i int, s *goquery.Selection) {
@@ -103,16 +82,16 @@ func parseExample(doc *goquery.Document) string {
 			preTag := s.NextFiltered("pre")
 			if preTag.Length() > 0 {
 				// Extract the code content inside <pre><code>
-				dupe[preTag.Find("code").Text()] = struct{}{}
+				code := preTag.Find("code").Text()
+				if _, ok := dupe[code]; !ok {
+					dupe[code] = struct{}{}
+					examples = append(examples, code)
+				}
 			}
 		}
 	})
 
-	for k := range dupe {
-		return k
-	}
-
-	return ""
+	return examples
 }
 
 type HTMLContent string
@@ -120,6 +99,11 @@ type Markdown = string
 
 // convert html to markdown
 func (c HTMLContent) ToMarkdown(year int) (Markdown, error) {
+	return c.toMarkdown(fmt.Sprintf("%s/%d/day/", DefaultBaseURL, year))
+}
+
+// toMarkdown converts the html to markdown and resolves relative links with the domain
+func (c HTMLContent) toMarkdown(domain string) (Markdown, error) {
 	conv := converter.NewConverter(
 		converter.WithPlugins(
 			base.NewBasePlugin(),
@@ -138,7 +122,7 @@ func (c HTMLContent) ToMarkdown(year int) (Markdown, error) {
 
 	conv.Register.RendererFor("em", converter.TagTypeInline, renderEmToBold, converter.PriorityEarly)
 
-	markdown, err := conv.ConvertString(string(c), converter.WithDomain(fmt.Sprintf("%s/%d/day/", BaseURL, year)))
+	markdown, err := conv.ConvertString(string(c), converter.WithDomain(domain))
 	if err != nil {
 		return "", fmt.Errorf("Failed to convert HTML to Markdown: %v", err)
 	}
@@ -0,0 +1,97 @@
+package aoc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRateInterval is the time it takes to regain the budget for one request
+	DefaultRateInterval = 3 * time.Second
+	// DefaultRateBurst is the number of requests which may be sent at once before requests get delayed
+	DefaultRateBurst = 3
+)
+
+// RateLimiter is a token bucket which limits how often requests are sent to the site.
+// It is safe for concurrent use, so all requests sharing a limiter share the same budget.
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    int
+	tokens   float64
+	last     time.Time
+
+	now func() time.Time
+}
+
+// NewRateLimiter returns a limiter which allows one request per interval with bursts of up to burst requests
+func NewRateLimiter(interval time.Duration, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		interval: interval,
+		burst:    burst,
+		tokens:   float64(burst),
+		now:      time.Now,
+	}
+}
+
+// reserve takes a token from the bucket and returns how long the caller has to wait until it may use it
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() && l.interval > 0 {
+		l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	}
+	l.last = now
+
+	// the bucket may go below zero, every waiting caller owns one of the missing tokens
+	l.tokens--
+	if l.tokens >= 0 || l.interval <= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens * float64(l.interval))
+}
+
+// cancel returns a token which was reserved but not used
+func (l *RateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens++
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+}
+
+// Wait blocks until a request may be sent or the context is done.
+// It returns how long the request was delayed.
+func (l *RateLimiter) Wait(ctx context.Context) (time.Duration, error) {
+	delay := l.reserve()
+	return delay, l.sleep(ctx, delay)
+}
+
+// sleep waits until the reserved token may be used, if the context is done first the token is given back
+func (l *RateLimiter) sleep(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	}
+}
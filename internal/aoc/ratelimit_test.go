@@ -0,0 +1,114 @@
+package aoc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock is a time which only moves when the test advances it
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestLimiter(interval time.Duration, burst int) (*RateLimiter, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2024, time.December, 1, 6, 0, 0, 0, time.UTC)}
+	l := NewRateLimiter(interval, burst)
+	l.now = clock.now
+	return l, clock
+}
+
+func TestRateLimiterBurst(t *testing.T) {
+	l, _ := newTestLimiter(time.Second, 3)
+
+	for i := range 3 {
+		if delay := l.reserve(); delay != 0 {
+			t.Errorf("request %d of the burst is delayed by %s", i+1, delay)
+		}
+	}
+	// every further request waits for one more interval
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second} {
+		if delay := l.reserve(); delay != want {
+			t.Errorf("request %d after the burst: delay = %s, want %s", i+1, delay, want)
+		}
+	}
+}
+
+func TestRateLimiterSpacing(t *testing.T) {
+	l, clock := newTestLimiter(2*time.Second, 1)
+
+	if delay := l.reserve(); delay != 0 {
+		t.Fatalf("first request is delayed by %s", delay)
+	}
+
+	clock.advance(2 * time.Second)
+	if delay := l.reserve(); delay != 0 {
+		t.Errorf("request after a full interval is delayed by %s", delay)
+	}
+
+	clock.advance(500 * time.Millisecond)
+	if delay := l.reserve(); delay != 1500*time.Millisecond {
+		t.Errorf("request after a quarter of the interval: delay = %s, want 1.5s", delay)
+	}
+}
+
+func TestRateLimiterRefillIsCapped(t *testing.T) {
+	l, clock := newTestLimiter(time.Second, 2)
+	l.reserve()
+
+	// a long pause doesn't save more than the burst
+	clock.advance(time.Hour)
+	for range 2 {
+		if delay := l.reserve(); delay != 0 {
+			t.Errorf("request of the burst is delayed by %s", delay)
+		}
+	}
+	if delay := l.reserve(); delay != time.Second {
+		t.Errorf("request after the burst: delay = %s, want 1s", delay)
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	l, _ := newTestLimiter(0, 1)
+	for range 5 {
+		if delay := l.reserve(); delay != 0 {
+			t.Errorf("request is delayed by %s without an interval", delay)
+		}
+	}
+}
+
+func TestRateLimiterWaitCanceled(t *testing.T) {
+	l, _ := newTestLimiter(time.Hour, 1)
+	l.reserve()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	delay, err := l.Wait(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if delay != time.Hour {
+		t.Errorf("delay = %s, want 1h", delay)
+	}
+
+	// the canceled request gave its token back, so the next one doesn't wait behind it
+	if delay := l.reserve(); delay != time.Hour {
+		t.Errorf("request after the canceled one: delay = %s, want 1h", delay)
+	}
+}
+
+func TestRateLimiterWaitWithoutDelay(t *testing.T) {
+	l, _ := newTestLimiter(time.Hour, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// a free token is used even if the context is already done, the request itself fails then
+	if delay, err := l.Wait(ctx); delay != 0 || err != nil {
+		t.Errorf("Wait = %s, %v, want 0, nil", delay, err)
+	}
+}
@@ -0,0 +1,124 @@
+package aoc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy describes how requests are retried after transient failures like a 502 or 503 at unlock time.
+// Only idempotent requests are retried, an answer is never submitted twice.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt, zero disables retrying
+	MaxRetries int
+	// BaseDelay is the delay before the first retry, it doubles with every retry
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts, a Retry-After header asking for longer stops retrying
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of a client if no other is set
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  time.Second,
+	MaxDelay:   30 * time.Second,
+}
+
+// backoff returns the exponential delay before the given retry with jitter,
+// so not every client hits the site at the same moment again
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.BaseDelay << retry
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// delay returns how long to wait before the given retry of a failed attempt and if it should be retried at all
+func (p RetryPolicy) delay(retry int, req *http.Request, resp *http.Response, err error) (time.Duration, bool) {
+	if retry >= p.MaxRetries || !isIdempotent(req) {
+		return 0, false
+	}
+
+	if err != nil {
+		// the request was canceled on purpose and not because of a transient failure
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return 0, false
+		}
+		return p.backoff(retry), true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+	default:
+		return 0, false
+	}
+
+	delay := p.backoff(retry)
+	if after, ok := retryAfter(resp); ok {
+		if p.MaxDelay > 0 && after > p.MaxDelay {
+			return 0, false
+		}
+		delay = max(delay, after)
+	}
+	return delay, true
+}
+
+// isIdempotent reports if the request can be sent again without side effects
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions:
+		return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	default:
+		return false
+	}
+}
+
+// retryAfter parses the Retry-After header which is either in seconds or a date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+// drain reads the rest of the body and closes it, so the connection can be reused for the retry
+func drain(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	resp.Body.Close()
+}
+
+// sleepContext waits for the duration or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,137 @@
+package aoc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func response(status int, retryAfter string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: make(http.Header)}
+	if retryAfter != "" {
+		resp.Header.Set("Retry-After", retryAfter)
+	}
+	return resp
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"120", 2 * time.Minute, true},
+		{"-5", 0, false},
+		{"soon", 0, false},
+		{"Wed, 21 Oct 2015 07:28:00 GMT", 0, true},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(response(http.StatusServiceUnavailable, tt.value))
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %s, %v, want %s, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	// a date is the time left until then
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	got, ok := retryAfter(response(http.StatusServiceUnavailable, date))
+	if !ok || got <= 50*time.Second || got > time.Minute {
+		t.Errorf("retryAfter(%q) = %s, %v, want about 1m", date, got, ok)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 2, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	get, _ := http.NewRequest("GET", "https://adventofcode.com/2024/day/1", nil)
+	post, _ := http.NewRequest("POST", "https://adventofcode.com/2024/day/1/answer", strings.NewReader("level=1&answer=42"))
+
+	tests := []struct {
+		name  string
+		retry int
+		req   *http.Request
+		resp  *http.Response
+		err   error
+		ok    bool
+	}{
+		{"unavailable", 0, get, response(http.StatusServiceUnavailable, ""), nil, true},
+		{"too many requests", 1, get, response(http.StatusTooManyRequests, ""), nil, true},
+		{"network error", 0, get, nil, errors.New("connection reset"), true},
+		{"not found", 0, get, response(http.StatusNotFound, ""), nil, false},
+		{"last retry used", 2, get, response(http.StatusServiceUnavailable, ""), nil, false},
+		{"canceled", 0, get, nil, context.Canceled, false},
+		{"deadline", 0, get, nil, context.DeadlineExceeded, false},
+		{"retry after longer than the max delay", 0, get, response(http.StatusServiceUnavailable, "60"), nil, false},
+		{"answer", 0, post, response(http.StatusServiceUnavailable, ""), nil, false},
+		{"answer with network error", 0, post, nil, errors.New("connection reset"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, ok := p.delay(tt.retry, tt.req, tt.resp, tt.err)
+			if ok != tt.ok {
+				t.Fatalf("retried = %v, want %v", ok, tt.ok)
+			}
+			if ok && (delay <= 0 || delay > p.MaxDelay) {
+				t.Errorf("delay = %s, want between 0 and %s", delay, p.MaxDelay)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelayHonorsRetryAfter(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: time.Minute}
+	get, _ := http.NewRequest("GET", "https://adventofcode.com/2024/day/1", nil)
+
+	delay, ok := p.delay(0, get, response(http.StatusTooManyRequests, "7"), nil)
+	if !ok || delay != 7*time.Second {
+		t.Errorf("delay = %s, %v, want 7s, true", delay, ok)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	for retry, limit := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		// the jitter keeps the delay between half and the full delay
+		if delay := p.backoff(retry); delay < limit/2 || delay > limit {
+			t.Errorf("backoff(%d) = %s, want between %s and %s", retry, delay, limit/2, limit)
+		}
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// every first request fails like at unlock time
+		if requests.Add(1)%2 == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("4\n5\n6\n"))
+	}))
+	defer server.Close()
+
+	policy := RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+	client := NewClient("session", WithBaseURL(server.URL), WithRateLimit(0, 0), WithRetry(policy))
+
+	if _, err := client.GetInput(2024, 1); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("input requested %d times, want 2", n)
+	}
+
+	// an answer is never sent twice, it might have been counted already
+	requests.Store(0)
+	if _, err := client.SubmitAnswer(LevelOne, 2024, 1, "15"); err == nil {
+		t.Error("the failed submission didn't return an error")
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("answer submitted %d times, want 1", n)
+	}
+}
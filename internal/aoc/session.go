@@ -0,0 +1,99 @@
+package aoc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ErrSessionExpired is returned when the site asks to log in although the client sends a session cookie
+var ErrSessionExpired = errors.New("the session is expired or invalid: log in to adventofcode.com again and update your session cookie")
+
+// errLoginRequired is the reason of a RequestError when the site asks to log in and the client has no session
+var errLoginRequired = errors.New("a session cookie is required, log in to adventofcode.com and provide your session cookie")
+
+// loginPrompts are the phrases of the site when a request is not logged in
+var loginPrompts = [][]byte{
+	[]byte("Please log in"),
+	[]byte("please identify yourself"),
+	[]byte("[Log In]"),
+}
+
+// isLoginPrompt reports if the response asks to log in
+func isLoginPrompt(body []byte) bool {
+	for _, prompt := range loginPrompts {
+		if bytes.Contains(body, prompt) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSession returns ErrSessionExpired if the client sent a session cookie but the response asks to log in
+func (c *Client) checkSession(body []byte) error {
+	if c.hasSession && isLoginPrompt(body) {
+		return ErrSessionExpired
+	}
+	return nil
+}
+
+// User is the account the session belongs to
+type User struct {
+	// ID is the number of the anonymous user, it is 0 if it couldn't be found
+	ID        int
+	Name      string
+	Supporter bool
+}
+
+var anonymousUserID = regexp.MustCompile(`anonymous user #(\d+)`)
+
+// WhoAmI fetches the settings of the account to check if the session is valid and who it belongs to
+func (c *Client) WhoAmI(ctx context.Context) (*User, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.SettingsURL(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := c.Request(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse HTML: %v", err)
+	}
+
+	// the user is shown in the header of every page, the settings redirect to the start page when not logged in
+	userDiv := doc.Find("header div.user")
+	if userDiv.Length() == 0 {
+		if !c.hasSession {
+			return nil, errLoginRequired
+		}
+		return nil, ErrSessionExpired
+	}
+
+	user := &User{
+		Name:      strings.TrimSpace(userDiv.Clone().Children().Remove().End().Text()),
+		Supporter: userDiv.Find(".supporter-badge").Length() > 0,
+	}
+	if m := anonymousUserID.FindSubmatch(body); m != nil {
+		user.ID, _ = strconv.Atoi(string(m[1]))
+	}
+
+	return user, nil
+}
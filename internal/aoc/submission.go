@@ -2,7 +2,9 @@ package aoc
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -14,7 +16,7 @@ type Level int
 
 const (
 	LevelOne Level = 1
-	LevelTwo
+	LevelTwo Level = 2
 )
 
 func ParseLevel(s string) Level {
@@ -63,12 +65,18 @@ func (e UnknownResponseError) Error() string {
 	return fmt.Sprintf("Unknown response: %d \n%s", e.StatusCode, e.Response)
 }
 
+// SubmitAnswer submits the answer for the level of the puzzle and returns the outcome
 func (c *Client) SubmitAnswer(level Level, year, day int, answer string) (SubmissionOutcome, error) {
+	return c.SubmitAnswerContext(context.Background(), level, year, day, answer)
+}
+
+// SubmitAnswerContext is like SubmitAnswer but the request is canceled when the context is done
+func (c *Client) SubmitAnswerContext(ctx context.Context, level Level, year, day int, answer string) (SubmissionOutcome, error) {
 	data := url.Values{}
 	data.Set("level", fmt.Sprintf("%d", level))
 	data.Set("answer", fmt.Sprintf("%s", answer))
 
-	req, err := http.NewRequest("POST", SubmitURL(year, day), bytes.NewBufferString(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.SubmitURL(year, day), bytes.NewBufferString(data.Encode()))
 	if err != nil {
 		return SubmissionError, fmt.Errorf("failed to create request: %v", err)
 	}
@@ -80,7 +88,16 @@ func (c *Client) SubmitAnswer(level Level, year, day int, answer string) (Submis
 	}
 	defer resp.Body.Close()
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return SubmissionError, fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	if err := c.checkSession(body); err != nil {
+		return SubmissionError, err
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
 	if err != nil {
 		return SubmissionError, fmt.Errorf("Failed to parse HTML: %v", err)
 	}
@@ -89,15 +106,18 @@ func (c *Client) SubmitAnswer(level Level, year, day int, answer string) (Submis
 
 	switch {
 	case strings.Contains(outcome, "That's the right answer"):
+		// the page of the puzzle changed with the answer, so the cached one is outdated
+		c.InvalidatePuzzle(year, day)
 		return SubmissionCorrect, nil
-	case strings.Contains(outcome, "That's not the right answer"):
-		return SubmissionIncorrect, nil
 	case strings.Contains(outcome, "You gave an answer too recently"):
 		return SubmissionWait, nil
 	case strings.Contains(outcome, "You don't seem to be solving the right level"):
 		return SubmissionWrongLevel, nil
+	// the answer of someone else is also "not the right answer", so it is checked first
 	case strings.Contains(outcome, "for someone else"):
 		return SubmissionOthersAnswer, nil
+	case strings.Contains(outcome, "That's not the right answer"):
+		return SubmissionIncorrect, nil
 	default:
 		return SubmissionError, UnknownResponseError{StatusCode: resp.StatusCode, Response: outcome}
 	}
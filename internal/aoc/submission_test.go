@@ -0,0 +1,74 @@
+package aoc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newAnswerServer starts a server which answers every submission with the message and records the sent level
+func newAnswerServer(t *testing.T, message string, level *string) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if level != nil {
+			*level = r.FormValue("level")
+		}
+		w.Write([]byte("<html><body><main><article><p>" + message + "</p></article></main></body></html>"))
+	}))
+	t.Cleanup(server.Close)
+	return NewClient("session", WithBaseURL(server.URL), WithRateLimit(0, 0))
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]Level{"1": LevelOne, "2": LevelTwo, "": LevelOne, "3": LevelOne}
+	for s, want := range tests {
+		if got := ParseLevel(s); got != want {
+			t.Errorf("ParseLevel(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
+
+func TestSubmitAnswerSendsLevel(t *testing.T) {
+	tests := []struct {
+		level Level
+		want  string
+	}{
+		{LevelOne, "1"},
+		// LevelTwo used to have the value 1, so part two was submitted as part one
+		{LevelTwo, "2"},
+	}
+	for _, tt := range tests {
+		var sent string
+		client := newAnswerServer(t, "That's the right answer!", &sent)
+		if _, err := client.SubmitAnswer(tt.level, 2024, 1, "42"); err != nil {
+			t.Fatal(err)
+		}
+		if sent != tt.want {
+			t.Errorf("level %d sent as %q, want %q", tt.level, sent, tt.want)
+		}
+	}
+}
+
+func TestSubmitAnswerOutcome(t *testing.T) {
+	tests := []struct {
+		message string
+		want    SubmissionOutcome
+	}{
+		{"That's the right answer! You are one gold star closer to finding the Chief Historian.", SubmissionCorrect},
+		{"That's not the right answer; your answer is too high.", SubmissionIncorrect},
+		{"You gave an answer too recently; you have to wait after submitting an answer before trying again.", SubmissionWait},
+		{"You don't seem to be solving the right level. Did you already complete it?", SubmissionWrongLevel},
+		// this message also says "not the right answer", it used to be reported as incorrect
+		{"That's not the right answer. Curiously, it's the right answer for someone else; you might be logged in to the wrong account.", SubmissionOthersAnswer},
+	}
+	for _, tt := range tests {
+		client := newAnswerServer(t, tt.message, nil)
+		got, err := client.SubmitAnswer(LevelOne, 2024, 1, "42")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%q: outcome = %v, want %v", tt.message, got, tt.want)
+		}
+	}
+}
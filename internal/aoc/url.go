@@ -2,20 +2,30 @@ package aoc
 
 import "fmt"
 
-const BaseURL = "https://adventofcode.com"
+// DefaultBaseURL is the address of Advent of Code which is used if no other base URL is set
+const DefaultBaseURL = "https://adventofcode.com"
 
-func CalendarURL(year int) string {
-	return fmt.Sprintf("%s/%d", BaseURL, year)
+// BaseURL returns the address of the site the client talks to
+func (c *Client) BaseURL() string {
+	return c.baseURL
 }
 
-func DayURL(year, day int) string {
-	return fmt.Sprintf("%s/%d/day/%d", BaseURL, year, day)
+func (c *Client) SettingsURL() string {
+	return fmt.Sprintf("%s/settings", c.baseURL)
 }
 
-func InputURL(year, day int) string {
-	return fmt.Sprintf("%s/%d/day/%d/input", BaseURL, year, day)
+func (c *Client) CalendarURL(year int) string {
+	return fmt.Sprintf("%s/%d", c.baseURL, year)
 }
 
-func SubmitURL(year, day int) string {
-	return fmt.Sprintf("%s/%d/day/%d/answer", BaseURL, year, day)
+func (c *Client) DayURL(year, day int) string {
+	return fmt.Sprintf("%s/%d/day/%d", c.baseURL, year, day)
+}
+
+func (c *Client) InputURL(year, day int) string {
+	return fmt.Sprintf("%s/%d/day/%d/input", c.baseURL, year, day)
+}
+
+func (c *Client) SubmitURL(year, day int) string {
+	return fmt.Sprintf("%s/%d/day/%d/answer", c.baseURL, year, day)
 }
@@ -0,0 +1,38 @@
+package aoc
+
+import "net/http"
+
+// userAgentName identifies aocli at the start of its user agent
+const userAgentName = "github.com/mitsimi/aocli"
+
+// UserAgent returns the user agent which identifies the tool and the contact of the user,
+// as asked for by the maintainers of Advent of Code for automated tools
+func UserAgent(version, contact string) string {
+	ua := userAgentName
+	if version != "" {
+		ua += " " + version
+	}
+	if contact != "" {
+		ua += " by " + contact
+	}
+	return ua
+}
+
+// userAgentTransport sets the User-Agent header on every request
+type userAgentTransport struct {
+	Transport http.RoundTripper
+	UserAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	// a RoundTripper must not modify the original request
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.UserAgent)
+
+	return transport.RoundTrip(req)
+}
@@ -0,0 +1,231 @@
+package aoctest
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.render(w, r, "Advent of Code", time.Now().Year(), indexPage, nil)
+}
+
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	year, err := strconv.Atoi(r.PathValue("year"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	var days []*Puzzle
+	for key, p := range s.puzzles {
+		if key.year == year {
+			days = append(days, p)
+		}
+	}
+	slices.SortFunc(days, func(a, b *Puzzle) int { return a.Day - b.Day })
+
+	s.render(w, r, fmt.Sprintf("Advent of Code %d", year), year, calendarPage, days)
+}
+
+func (s *Server) handleDay(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.puzzle(w, r)
+	if !ok {
+		return
+	}
+
+	data := struct {
+		*Puzzle
+		PartOne  template.HTML
+		PartTwo  template.HTML
+		LoggedIn bool
+		Complete bool
+		Level    int
+	}{
+		Puzzle:   p,
+		PartOne:  template.HTML(p.PartOne),
+		PartTwo:  template.HTML(p.PartTwo),
+		LoggedIn: s.loggedIn(r),
+		Complete: p.Complete(),
+		Level:    p.Solved + 1,
+	}
+
+	s.render(w, r, fmt.Sprintf("Day %d - Advent of Code %d", p.Day, p.Year), p.Year, dayPage, data)
+}
+
+func (s *Server) handleInput(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.puzzle(w, r)
+	if !ok {
+		return
+	}
+
+	if !s.loggedIn(r) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "Puzzle inputs differ by user.  Please log in to get your puzzle input.\n")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	fmt.Fprint(w, p.Input)
+}
+
+func (s *Server) handleAnswer(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.puzzle(w, r)
+	if !ok {
+		return
+	}
+
+	if !s.loggedIn(r) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "To play, please identify yourself via one of these services.\n")
+		return
+	}
+
+	level, _ := strconv.Atoi(r.PostFormValue("level"))
+	answer := strings.TrimSpace(r.PostFormValue("answer"))
+
+	key := puzzleKey{p.Year, p.Day}
+	var outcome Outcome
+	if queued := s.outcomes[key]; len(queued) > 0 {
+		outcome, s.outcomes[key] = queued[0], queued[1:]
+	} else {
+		outcome = p.check(level, answer)
+	}
+
+	if outcome.Kind == OutcomeCorrect && level == p.Solved+1 {
+		p.Solved = level
+	}
+	s.submissions = append(s.submissions, Submission{Year: p.Year, Day: p.Day, Level: level, Answer: answer, Outcome: outcome})
+
+	data := struct {
+		Year int
+		Day  int
+		Kind string
+		Hint string
+		Wait string
+	}{Year: p.Year, Day: p.Day, Wait: formatWait(outcome.Wait)}
+
+	switch outcome.Kind {
+	case OutcomeCorrect:
+		data.Kind = "correct"
+	case OutcomeIncorrect:
+		data.Kind = "incorrect"
+	case OutcomeTooHigh:
+		data.Kind, data.Hint = "incorrect", "; your answer is too high"
+	case OutcomeTooLow:
+		data.Kind, data.Hint = "incorrect", "; your answer is too low"
+	case OutcomeWait:
+		data.Kind = "wait"
+	case OutcomeOthersAnswer:
+		data.Kind = "others"
+	default:
+		data.Kind = "wrong-level"
+	}
+
+	s.render(w, r, fmt.Sprintf("Day %d - Advent of Code %d", p.Day, p.Year), p.Year, answerPage, data)
+}
+
+func (s *Server) handleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	year, err := strconv.Atoi(r.PathValue("year"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	data := struct {
+		Year  int
+		Stars int
+		User  User
+	}{Year: year, Stars: s.stars(year), User: s.User}
+
+	s.render(w, r, fmt.Sprintf("Leaderboard - Advent of Code %d", year), year, leaderboardPage, data)
+}
+
+// handlePrivateLeaderboard serves the JSON api of a private leaderboard with the logged in user as only member
+func (s *Server) handlePrivateLeaderboard(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	year, err1 := strconv.Atoi(r.PathValue("year"))
+	id, err2 := strconv.Atoi(strings.TrimSuffix(r.PathValue("id"), ".json"))
+	if err1 != nil || err2 != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if !s.loggedIn(r) {
+		http.Redirect(w, r, fmt.Sprintf("/%d/leaderboard/private", year), http.StatusFound)
+		return
+	}
+
+	type star struct {
+		GetStarTS int64 `json:"get_star_ts"`
+		StarIndex int   `json:"star_index"`
+	}
+	type member struct {
+		ID                 int                        `json:"id"`
+		Name               string                     `json:"name"`
+		Stars              int                        `json:"stars"`
+		LocalScore         int                        `json:"local_score"`
+		GlobalScore        int                        `json:"global_score"`
+		LastStarTS         int64                      `json:"last_star_ts"`
+		CompletionDayLevel map[string]map[string]star `json:"completion_day_level"`
+	}
+
+	m := member{ID: s.User.ID, Name: s.User.Name, CompletionDayLevel: make(map[string]map[string]star)}
+	for key, p := range s.puzzles {
+		if key.year != year || p.Solved == 0 {
+			continue
+		}
+		levels := make(map[string]star)
+		for level := 1; level <= min(p.Solved, 2); level++ {
+			levels[strconv.Itoa(level)] = star{}
+			m.Stars++
+			m.LocalScore++
+		}
+		m.CompletionDayLevel[strconv.Itoa(p.Day)] = levels
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"event":    strconv.Itoa(year),
+		"owner_id": id,
+		"members":  map[string]member{strconv.Itoa(m.ID): m},
+	})
+}
+
+func (s *Server) handleSettings(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// like the real site the settings redirect to the start page when not logged in
+	if !s.loggedIn(r) {
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+
+	s.render(w, r, "Settings - Advent of Code", time.Now().Year(), settingsPage, s.User)
+}
@@ -0,0 +1,137 @@
+package aoctest
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+)
+
+// the pages mimic the markup of adventofcode.com, as far as aocli and similar tools rely on it
+var pages = template.Must(template.New("layout").Parse(`<!DOCTYPE html>
+<html lang="en-us">
+<head>
+<meta charset="utf-8"/>
+<title>{{.Title}}</title>
+</head><!--
+
+Oh, hello!  This is a fake Advent of Code for testing.
+
+-->
+<body>
+<header><div><h1 class="title-global"><a href="/">Advent of Code</a></h1>
+{{- if .User}}<div class="user">{{.User.Name}}{{if .User.Supporter}} <a href="/{{.Year}}/support" class="supporter-badge" title="Advent of Code Supporter">AoC++</a>{{end}} <span class="star-count">{{.Stars}}*</span></div>
+{{- else}}<div><a href="/{{.Year}}/auth/login">[Log In]</a></div>{{end}}</div></header>
+<main>
+{{.Main}}
+</main>
+</body>
+</html>
+`))
+
+var indexPage = template.Must(template.New("index").Parse(`<article><p>Advent of Code is an Advent calendar of small programming puzzles.</p></article>`))
+
+var dayPage = template.Must(template.New("day").Parse(`<article class="day-desc"><h2>--- Day {{.Day}}: {{.Title}} ---</h2>{{.PartOne}}</article>
+{{- if .LoggedIn}}
+{{- if ge .Solved 1}}
+<p>Your puzzle answer was <code>{{index .Answers 0}}</code>.</p>
+{{- if ne .Day 25}}<article class="day-desc"><h2 id="part2">--- Part Two ---</h2>{{.PartTwo}}</article>{{end}}
+{{- end}}
+{{- if ge .Solved 2}}
+<p>Your puzzle answer was <code>{{index .Answers 1}}</code>.</p>
+{{- end}}
+{{- if .Complete}}
+<p class="day-success">Both parts of this puzzle are complete! They provide two gold stars: **</p>
+<p>At this point, you should <a href="/{{.Year}}">return to your Advent calendar</a> and try another puzzle.</p>
+<p>If you still want to see it, you can <a href="{{.Day}}/input" target="_blank">get your puzzle input</a>.</p>
+{{- else}}
+<p>{{if eq .Solved 0}}To begin, {{else}}Although it hasn't changed, you can still {{end}}<a href="{{.Day}}/input" target="_blank">get your puzzle input</a>.</p>
+<form method="post" action="{{.Day}}/answer"><input type="hidden" name="level" value="{{.Level}}"/><p>Answer: <input type="text" name="answer" autocomplete="off"/> <input type="submit" value="[Submit]"/></p></form>
+{{- end}}
+{{- else}}
+<p>To play, please identify yourself via one of these services:</p>
+<p><a href="/auth/github">[GitHub]</a> <a href="/auth/google">[Google]</a> <a href="/auth/twitter">[Twitter]</a> <a href="/auth/reddit">[Reddit]</a></p>
+{{- end}}`))
+
+var answerPage = template.Must(template.New("answer").Parse(`<article><p>
+{{- if eq .Kind "correct"}}That's the right answer!  You are <span class="day-success">one gold star</span> closer to saving Christmas. <a href="/{{.Year}}/day/{{.Day}}#part2">[Continue to Part Two]</a>
+{{- else if eq .Kind "incorrect"}}That's not the right answer{{.Hint}}.  If you're stuck, make sure you're using the full input data; there are also some general tips on the <a href="/{{.Year}}/about">about page</a>.  Please wait one minute before trying again. <a href="/{{.Year}}/day/{{.Day}}">[Return to Day {{.Day}}]</a>
+{{- else if eq .Kind "others"}}That's not the right answer.  Curiously, it's the right answer for someone else; you might be logged in to the wrong account or just unlucky. <a href="/{{.Year}}/day/{{.Day}}">[Return to Day {{.Day}}]</a>
+{{- else if eq .Kind "wait"}}You gave an answer too recently; you have to wait after submitting an answer before trying again.  You have {{.Wait}} left to wait. <a href="/{{.Year}}/day/{{.Day}}">[Return to Day {{.Day}}]</a>
+{{- else}}You don't seem to be solving the right level.  Did you already complete it? <a href="/{{.Year}}/day/{{.Day}}">[Return to Day {{.Day}}]</a>
+{{- end}}</p></article>`))
+
+var calendarPage = template.Must(template.New("calendar").Parse(`<pre class="calendar">
+{{- range .}}
+<a aria-label="Day {{.Day}}{{if .Complete}}, two stars{{else if ge .Solved 1}}, one star{{end}}" href="/{{.Year}}/day/{{.Day}}" class="calendar-day{{.Day}}{{if .Complete}} calendar-verycomplete{{else if ge .Solved 1}} calendar-complete{{end}}"><span class="calendar-day">{{printf "%2d" .Day}}</span> <span class="calendar-mark-complete">*</span><span class="calendar-mark-verycomplete">*</span></a>
+{{- end}}
+</pre>`))
+
+var leaderboardPage = template.Must(template.New("leaderboard").Parse(`<article><p>Below is the <em>Advent of Code {{.Year}}</em> overall leaderboard; these are the users with the most stars.</p>
+<div class="leaderboard-entry"><span class="leaderboard-position">  1)</span> <span class="leaderboard-totalscore">{{printf "%4d" .Stars}}</span> {{.User.Name}}</div>
+</article>`))
+
+var settingsPage = template.Must(template.New("settings").Parse(`<article><p>You can change your display name in the leaderboards.</p>
+<form method="post" action="/settings">
+<div><label><input type="radio" name="display_name" value="0"/> (anonymous user #{{.ID}})</label></div>
+<div><label><input type="radio" name="display_name" value="1" checked="checked"/> {{.Name}}</label></div>
+</form>
+{{- if .Supporter}}
+<p>Thank you for supporting Advent of Code!</p>
+{{- else}}
+<p>If you like Advent of Code, you can <a href="/support">support it</a>.</p>
+{{- end}}
+</article>`))
+
+type layoutData struct {
+	Title string
+	Year  int
+	User  *User
+	Stars int
+	Main  template.HTML
+}
+
+// render writes a page of the site with the content of the template in its <main> tag.
+// The caller has to hold the lock of the server.
+func (s *Server) render(w http.ResponseWriter, r *http.Request, title string, year int, tmpl *template.Template, data any) {
+	var main bytes.Buffer
+	if err := tmpl.Execute(&main, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	layout := layoutData{Title: title, Year: year, Main: template.HTML(main.String())}
+	if s.loggedIn(r) {
+		layout.User = &s.User
+		layout.Stars = s.stars(year)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	pages.Execute(w, layout)
+}
+
+// stars returns the number of stars the user collected in the year.
+// The caller has to hold the lock of the server.
+func (s *Server) stars(year int) int {
+	stars := 0
+	for key, p := range s.puzzles {
+		if key.year != year {
+			continue
+		}
+		stars += min(p.Solved, 2)
+		if p.Day == 25 && p.Solved == 1 {
+			stars++
+		}
+	}
+	return stars
+}
+
+// formatWait formats the time left to wait like the real site, e.g. "4m 33s"
+func formatWait(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d >= time.Minute {
+		return fmt.Sprintf("%dm %ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%ds", int(d.Seconds()))
+}
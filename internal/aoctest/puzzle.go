@@ -0,0 +1,125 @@
+package aoctest
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Puzzle is a puzzle served by the fake server
+type Puzzle struct {
+	Year  int
+	Day   int
+	Title string
+
+	// PartOne and PartTwo are the html of the descriptions below the heading of each part
+	PartOne string
+	PartTwo string
+
+	// Input is the puzzle input of the logged in user
+	Input string
+	// Answers are the correct answers of both parts
+	Answers [2]string
+
+	// Solved is the number of parts the logged in user has solved
+	Solved int
+}
+
+// NewPuzzle returns a puzzle with a description containing an example for each part
+func NewPuzzle(year, day int) *Puzzle {
+	return &Puzzle{
+		Year:  year,
+		Day:   day,
+		Title: fmt.Sprintf("Puzzle %d", day),
+		PartOne: `<p>The elves need your help counting the numbers in a list.</p>
+<p>For example:</p>
+<pre><code>1
+2
+3
+</code></pre>
+<p>In this example, the sum is <code><em>6</em></code>.</p>
+<p>What is the sum of all numbers in your list?</p>`,
+		PartTwo: `<p>Now the elves need the product instead.</p>
+<p>For example, using the same list, the product is <code><em>6</em></code> as well.</p>
+<p>What is the product of all numbers in your list?</p>`,
+		Input:   "4\n5\n6\n",
+		Answers: [2]string{"15", "120"},
+	}
+}
+
+// Complete reports if both parts of the puzzle are solved.
+// On the last day the second star is given for all other stars, so it is complete after the first part.
+func (p *Puzzle) Complete() bool {
+	return p.Solved >= 2 || (p.Day == 25 && p.Solved >= 1)
+}
+
+// OutcomeKind is the kind of response to a submitted answer
+type OutcomeKind int
+
+const (
+	// OutcomeCorrect accepts the answer and solves the level
+	OutcomeCorrect OutcomeKind = iota
+	// OutcomeIncorrect rejects the answer without a hint
+	OutcomeIncorrect
+	// OutcomeTooHigh rejects the answer with the hint that it is too high
+	OutcomeTooHigh
+	// OutcomeTooLow rejects the answer with the hint that it is too low
+	OutcomeTooLow
+	// OutcomeWait rejects the answer because the last one was submitted too recently
+	OutcomeWait
+	// OutcomeWrongLevel rejects the answer because the level is already solved or not unlocked yet
+	OutcomeWrongLevel
+	// OutcomeOthersAnswer rejects the answer because it is the answer of someone else
+	OutcomeOthersAnswer
+)
+
+// Outcome is the response to a submitted answer
+type Outcome struct {
+	Kind OutcomeKind
+	// Wait is the time left to wait which is shown for OutcomeWait
+	Wait time.Duration
+}
+
+// Correct returns the outcome for an accepted answer
+func Correct() Outcome { return Outcome{Kind: OutcomeCorrect} }
+
+// Incorrect returns the outcome for a rejected answer without hint
+func Incorrect() Outcome { return Outcome{Kind: OutcomeIncorrect} }
+
+// TooHigh returns the outcome for an answer which is too high
+func TooHigh() Outcome { return Outcome{Kind: OutcomeTooHigh} }
+
+// TooLow returns the outcome for an answer which is too low
+func TooLow() Outcome { return Outcome{Kind: OutcomeTooLow} }
+
+// Wait returns the outcome for an answer submitted too recently with the time left to wait
+func Wait(left time.Duration) Outcome { return Outcome{Kind: OutcomeWait, Wait: left} }
+
+// WrongLevel returns the outcome for an answer of a level which can't be solved
+func WrongLevel() Outcome { return Outcome{Kind: OutcomeWrongLevel} }
+
+// OthersAnswer returns the outcome for an answer which is correct for another account
+func OthersAnswer() Outcome { return Outcome{Kind: OutcomeOthersAnswer} }
+
+// check decides the outcome of an answer like the real site, when no outcome is scripted
+func (p *Puzzle) check(level int, answer string) Outcome {
+	if level != p.Solved+1 || p.Complete() {
+		return WrongLevel()
+	}
+
+	correct := p.Answers[level-1]
+	if answer == correct {
+		return Correct()
+	}
+
+	// numeric answers get a hint in which direction they are wrong
+	a, ok1 := new(big.Int).SetString(answer, 10)
+	c, ok2 := new(big.Int).SetString(correct, 10)
+	if ok1 && ok2 {
+		if a.Cmp(c) > 0 {
+			return TooHigh()
+		}
+		return TooLow()
+	}
+	return Incorrect()
+}
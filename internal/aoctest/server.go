@@ -0,0 +1,157 @@
+// Package aoctest provides a fake Advent of Code server for testing aocli and tools built on it offline.
+package aoctest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+
+	"github.com/mitsimi/aocli/internal/aoc"
+)
+
+// User is the account which is logged in with the session of the server
+type User struct {
+	ID        int
+	Name      string
+	Supporter bool
+}
+
+// Server is a fake Advent of Code which serves puzzles from memory.
+// Only requests with the session cookie of the server are logged in, like on the real site.
+type Server struct {
+	*httptest.Server
+
+	// Session is the session cookie which is accepted by the server
+	Session string
+	// User is the account which is logged in with the session
+	User User
+
+	mu          sync.Mutex
+	puzzles     map[puzzleKey]*Puzzle
+	outcomes    map[puzzleKey][]Outcome
+	submissions []Submission
+	requests    []string
+}
+
+type puzzleKey struct {
+	year int
+	day  int
+}
+
+// Submission is an answer which was submitted to the server
+type Submission struct {
+	Year    int
+	Day     int
+	Level   int
+	Answer  string
+	Outcome Outcome
+}
+
+// NewServer starts a server which accepts the given session cookie.
+// The caller has to call Close when finished.
+func NewServer(session string) *Server {
+	s := &Server{
+		Session:  session,
+		User:     User{ID: 1, Name: "aocli tester"},
+		puzzles:  make(map[puzzleKey]*Puzzle),
+		outcomes: make(map[puzzleKey][]Outcome),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleIndex)
+	mux.HandleFunc("GET /{year}", s.handleCalendar)
+	mux.HandleFunc("GET /{year}/day/{day}", s.handleDay)
+	mux.HandleFunc("GET /{year}/day/{day}/input", s.handleInput)
+	mux.HandleFunc("POST /{year}/day/{day}/answer", s.handleAnswer)
+	mux.HandleFunc("GET /{year}/leaderboard", s.handleLeaderboard)
+	mux.HandleFunc("GET /{year}/leaderboard/private/view/{id}", s.handlePrivateLeaderboard)
+	mux.HandleFunc("GET /settings", s.handleSettings)
+
+	s.Server = httptest.NewServer(s.record(mux))
+	return s
+}
+
+// NewClient returns a client talking to the server with its session.
+// Rate limiting and retries are disabled, so tests don't have to wait.
+func (s *Server) NewClient(options ...aoc.Option) *aoc.Client {
+	options = append([]aoc.Option{
+		aoc.WithBaseURL(s.URL),
+		aoc.WithRateLimit(0, 0),
+		aoc.WithRetry(aoc.RetryPolicy{}),
+	}, options...)
+	return aoc.NewClient(s.Session, options...)
+}
+
+// AddPuzzle adds the puzzle to the server or replaces the one of the same day
+func (s *Server) AddPuzzle(p *Puzzle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.puzzles[puzzleKey{p.Year, p.Day}] = p
+}
+
+// Puzzle returns the puzzle of the day or nil if there is none
+func (s *Server) Puzzle(year, day int) *Puzzle {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.puzzles[puzzleKey{year, day}]
+}
+
+// QueueOutcome scripts the outcome of the next submission for the puzzle.
+// Without a queued outcome the answer is checked against the answer of the puzzle.
+func (s *Server) QueueOutcome(year, day int, outcomes ...Outcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := puzzleKey{year, day}
+	s.outcomes[key] = append(s.outcomes[key], outcomes...)
+}
+
+// Submissions returns all answers which were submitted to the server
+func (s *Server) Submissions() []Submission {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Submission(nil), s.submissions...)
+}
+
+// Requests returns the method and path of every request the server received, e.g. "GET /2024/day/1"
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// record remembers every request before it is handled
+func (s *Server) record(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		s.mu.Unlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// loggedIn reports if the request carries the session cookie of the server
+func (s *Server) loggedIn(r *http.Request) bool {
+	cookie, err := r.Cookie("session")
+	return err == nil && s.Session != "" && cookie.Value == s.Session
+}
+
+// puzzle returns the puzzle of the path values or writes a 404 like the real site.
+// The caller has to hold the lock of the server.
+func (s *Server) puzzle(w http.ResponseWriter, r *http.Request) (*Puzzle, bool) {
+	year, err1 := strconv.Atoi(r.PathValue("year"))
+	day, err2 := strconv.Atoi(r.PathValue("day"))
+	if err1 != nil || err2 != nil {
+		http.NotFound(w, r)
+		return nil, false
+	}
+
+	p := s.puzzles[puzzleKey{year, day}]
+	if p == nil {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "404 Not Found")
+		return nil, false
+	}
+	return p, true
+}
@@ -0,0 +1,276 @@
+package aoctest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSession = "test-session"
+
+// newTestServer starts a server with day 1 of 2024
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	s := NewServer(testSession)
+	t.Cleanup(s.Close)
+	s.AddPuzzle(NewPuzzle(2024, 1))
+	return s
+}
+
+// do sends the request with the session, if it isn't empty, and returns the status and body.
+// Redirects aren't followed, so they can be checked.
+func do(t *testing.T, method, target, session string, form url.Values) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, target, strings.NewReader(form.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if session != "" {
+		req.AddCookie(&http.Cookie{Name: "session", Value: session})
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestLogin(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		name     string
+		session  string
+		loggedIn bool
+	}{
+		{"session", testSession, true},
+		{"no session", "", false},
+		{"other session", "other", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, body := do(t, "GET", s.URL+"/2024/day/1", tt.session, nil)
+			if got := strings.Contains(body, "aocli tester"); got != tt.loggedIn {
+				t.Errorf("logged in = %v, want %v:\n%s", got, tt.loggedIn, body)
+			}
+			if got := strings.Contains(body, "/auth/github"); got == tt.loggedIn {
+				t.Errorf("login links shown = %v, want %v", got, !tt.loggedIn)
+			}
+
+			status, body := do(t, "GET", s.URL+"/2024/day/1/input", tt.session, nil)
+			if tt.loggedIn && (status != http.StatusOK || body != "4\n5\n6\n") {
+				t.Errorf("input = %d %q, want the input", status, body)
+			}
+			if !tt.loggedIn && status != http.StatusBadRequest {
+				t.Errorf("input status = %d, want 400", status)
+			}
+
+			status, _ = do(t, "GET", s.URL+"/settings", tt.session, nil)
+			if want := map[bool]int{true: http.StatusOK, false: http.StatusFound}[tt.loggedIn]; status != want {
+				t.Errorf("settings status = %d, want %d", status, want)
+			}
+		})
+	}
+}
+
+func TestUnknownPuzzle(t *testing.T) {
+	s := newTestServer(t)
+	for _, path := range []string{"/2024/day/2", "/2024/day/2/input", "/2023/day/1", "/2024/day/one"} {
+		if status, _ := do(t, "GET", s.URL+path, testSession, nil); status != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404", path, status)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		day    int
+		solved int
+		level  int
+		answer string
+		want   OutcomeKind
+	}{
+		{"correct", 1, 0, 1, "15", OutcomeCorrect},
+		{"too high", 1, 0, 1, "16", OutcomeTooHigh},
+		{"too low", 1, 0, 1, "14", OutcomeTooLow},
+		{"not a number", 1, 0, 1, "fifteen", OutcomeIncorrect},
+		{"big number", 1, 0, 1, "123456789012345678901234567890", OutcomeTooHigh},
+		{"second part", 1, 1, 2, "120", OutcomeCorrect},
+		{"answer of the first part for the second", 1, 1, 2, "15", OutcomeTooLow},
+		{"solved level", 1, 1, 1, "15", OutcomeWrongLevel},
+		{"locked level", 1, 0, 2, "120", OutcomeWrongLevel},
+		{"complete", 1, 2, 2, "120", OutcomeWrongLevel},
+		{"last day is complete after the first part", 25, 1, 2, "120", OutcomeWrongLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPuzzle(2024, tt.day)
+			p.Solved = tt.solved
+			if got := p.check(tt.level, tt.answer); got.Kind != tt.want {
+				t.Errorf("outcome = %v, want %v", got.Kind, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnswer(t *testing.T) {
+	s := newTestServer(t)
+	answer := func(level, answer string) string {
+		t.Helper()
+		status, body := do(t, "POST", s.URL+"/2024/day/1/answer", testSession, url.Values{"level": {level}, "answer": {answer}})
+		if status != http.StatusOK {
+			t.Fatalf("status = %d, want 200", status)
+		}
+		return body
+	}
+
+	if body := answer("1", "14"); !strings.Contains(body, "your answer is too low") {
+		t.Errorf("the hint is missing:\n%s", body)
+	}
+	if body := answer("1", " 15\n"); !strings.Contains(body, "That's the right answer") {
+		t.Errorf("the trimmed answer wasn't accepted:\n%s", body)
+	}
+	if p := s.Puzzle(2024, 1); p.Solved != 1 {
+		t.Errorf("solved = %d, want 1", p.Solved)
+	}
+
+	// the scripted outcomes come first and don't solve the level unless they are correct
+	s.QueueOutcome(2024, 1, Wait(4*time.Minute+33*time.Second), OthersAnswer())
+	if body := answer("2", "120"); !strings.Contains(body, "You have 4m 33s left to wait") {
+		t.Errorf("the time to wait is missing:\n%s", body)
+	}
+	if body := answer("2", "120"); !strings.Contains(body, "right answer for someone else") {
+		t.Errorf("the answer of someone else isn't reported:\n%s", body)
+	}
+	if p := s.Puzzle(2024, 1); p.Solved != 1 {
+		t.Errorf("solved = %d after the scripted outcomes, want 1", p.Solved)
+	}
+	if body := answer("2", "120"); !strings.Contains(body, "That's the right answer") {
+		t.Errorf("the answer wasn't checked after the scripted outcomes:\n%s", body)
+	}
+	if body := answer("2", "120"); !strings.Contains(body, "Did you already complete it?") {
+		t.Errorf("the complete puzzle accepted an answer:\n%s", body)
+	}
+
+	var kinds []OutcomeKind
+	for _, sub := range s.Submissions() {
+		kinds = append(kinds, sub.Outcome.Kind)
+	}
+	want := []OutcomeKind{OutcomeTooLow, OutcomeCorrect, OutcomeWait, OutcomeOthersAnswer, OutcomeCorrect, OutcomeWrongLevel}
+	if !slices.Equal(kinds, want) {
+		t.Errorf("submissions = %v, want %v", kinds, want)
+	}
+	if sub := s.Submissions()[1]; sub.Year != 2024 || sub.Day != 1 || sub.Level != 1 || sub.Answer != "15" {
+		t.Errorf("submission = %+v", sub)
+	}
+
+	// an answer without session isn't counted
+	if status, _ := do(t, "POST", s.URL+"/2024/day/1/answer", "", url.Values{"level": {"1"}, "answer": {"15"}}); status != http.StatusBadRequest {
+		t.Errorf("status without session = %d, want 400", status)
+	}
+	if n := len(s.Submissions()); n != len(want) {
+		t.Errorf("submissions = %d after the answer without session, want %d", n, len(want))
+	}
+}
+
+func TestDayPage(t *testing.T) {
+	s := newTestServer(t)
+	s.AddPuzzle(NewPuzzle(2024, 25))
+
+	tests := []struct {
+		name    string
+		day     int
+		solved  int
+		partTwo bool
+		form    bool
+	}{
+		{"unsolved", 1, 0, false, true},
+		{"first part solved", 1, 1, true, true},
+		{"complete", 1, 2, true, false},
+		{"last day", 25, 1, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := s.Puzzle(2024, tt.day)
+			p.Solved = tt.solved
+			_, body := do(t, "GET", fmt.Sprintf("%s/2024/day/%d", s.URL, tt.day), testSession, nil)
+			if got := strings.Contains(body, "--- Part Two ---"); got != tt.partTwo {
+				t.Errorf("part two shown = %v, want %v", got, tt.partTwo)
+			}
+			if got := strings.Contains(body, `name="level"`); got != tt.form {
+				t.Errorf("answer form shown = %v, want %v", got, tt.form)
+			}
+		})
+	}
+}
+
+func TestStars(t *testing.T) {
+	s := newTestServer(t)
+	s.Puzzle(2024, 1).Solved = 2
+	last := NewPuzzle(2024, 25)
+	last.Solved = 1
+	s.AddPuzzle(last)
+	other := NewPuzzle(2023, 1)
+	other.Solved = 1
+	s.AddPuzzle(other)
+
+	if _, body := do(t, "GET", s.URL+"/2024", testSession, nil); !strings.Contains(body, `<span class="star-count">4*</span>`) {
+		t.Errorf("the stars of 2024 are wrong:\n%s", body)
+	}
+	if _, body := do(t, "GET", s.URL+"/2024/leaderboard", testSession, nil); !strings.Contains(body, "   4</span> aocli tester") {
+		t.Errorf("the leaderboard is wrong:\n%s", body)
+	}
+
+	status, body := do(t, "GET", s.URL+"/2024/leaderboard/private/view/7.json", testSession, nil)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	var board struct {
+		Event   string `json:"event"`
+		OwnerID int    `json:"owner_id"`
+		Members map[string]struct {
+			Stars              int                       `json:"stars"`
+			CompletionDayLevel map[string]map[string]any `json:"completion_day_level"`
+		} `json:"members"`
+	}
+	if err := json.Unmarshal([]byte(body), &board); err != nil {
+		t.Fatal(err)
+	}
+	member := board.Members["1"]
+	if board.Event != "2024" || board.OwnerID != 7 || member.Stars != 3 || len(member.CompletionDayLevel["1"]) != 2 || len(member.CompletionDayLevel["25"]) != 1 {
+		t.Errorf("private leaderboard = %+v", board)
+	}
+
+	if status, _ := do(t, "GET", s.URL+"/2024/leaderboard/private/view/7.json", "", nil); status != http.StatusFound {
+		t.Errorf("status without session = %d, want 302", status)
+	}
+}
+
+func TestRequests(t *testing.T) {
+	s := newTestServer(t)
+	do(t, "GET", s.URL+"/2024/day/1", testSession, nil)
+	do(t, "GET", s.URL+"/2024/day/1/input", "", nil)
+	do(t, "POST", s.URL+"/2024/day/1/answer", testSession, url.Values{"level": {"1"}, "answer": {"15"}})
+
+	want := []string{"GET /2024/day/1", "GET /2024/day/1/input", "POST /2024/day/1/answer"}
+	if got := s.Requests(); !slices.Equal(got, want) {
+		t.Errorf("requests = %v, want %v", got, want)
+	}
+}
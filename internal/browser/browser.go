@@ -0,0 +1,132 @@
+package browser
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// ErrNoCookie is returned if the browser has no session cookie for the site
+var ErrNoCookie = errors.New("no session cookie found")
+
+// Browsers are the names of the supported browsers
+var Browsers = []string{"firefox", "chromium"}
+
+// SessionCookie reads the session cookie of the host from the cookie database of the browser.
+// The profile is the profile directory or the cookie database itself, if it is empty the most recently used profile is taken.
+func SessionCookie(browser string, profile string, host string) (string, error) {
+	switch browser {
+	case "firefox":
+		return firefoxCookie(profile, host)
+	case "chromium", "chrome":
+		return chromiumCookie(profile, host)
+	default:
+		return "", fmt.Errorf("unsupported browser: %s (use %s)", browser, strings.Join(Browsers, " or "))
+	}
+}
+
+// findDatabase returns the cookie database of the profile or the most recently changed one of the candidates
+func findDatabase(profile string, names []string, candidates []string) (string, error) {
+	if profile != "" {
+		info, err := os.Stat(profile)
+		if err != nil {
+			return "", err
+		}
+		if !info.IsDir() {
+			return profile, nil
+		}
+		candidates = nil
+		for _, name := range names {
+			candidates = append(candidates, filepath.Join(profile, name))
+		}
+	}
+
+	var newest string
+	var newestInfo os.FileInfo
+	for _, path := range candidates {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if newestInfo == nil || info.ModTime().After(newestInfo.ModTime()) {
+			newest, newestInfo = path, info
+		}
+	}
+
+	if newest == "" {
+		if profile != "" {
+			return "", fmt.Errorf("no cookie database found in %s", profile)
+		}
+		return "", fmt.Errorf("no browser profile found, set it with --browser-profile")
+	}
+	return newest, nil
+}
+
+// globAll returns the matches of all patterns
+func globAll(patterns ...string) []string {
+	var matches []string
+	for _, pattern := range patterns {
+		m, _ := filepath.Glob(pattern)
+		matches = append(matches, m...)
+	}
+	return matches
+}
+
+// openCopy opens a copy of the database, because the browser locks it while running.
+// The write-ahead log is copied as well, it contains the cookies which aren't written back yet.
+func openCopy(path string) (*sql.DB, func(), error) {
+	dir, err := os.MkdirTemp("", "aocli-cookies")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	dst := filepath.Join(dir, filepath.Base(path))
+	for _, suffix := range []string{"", "-wal"} {
+		err := copyFile(path+suffix, dst+suffix)
+		if err != nil && !(suffix != "" && errors.Is(err, os.ErrNotExist)) {
+			cleanup()
+			return nil, nil, err
+		}
+	}
+
+	// the copy is opened for writing, read-only sqlite can't replay the write-ahead log without its shared memory file
+	db, err := sql.Open("sqlite", "file:"+dst)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return db, func() {
+		db.Close()
+		cleanup()
+	}, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
+
+// cookieHosts are the values of the host column which match the host, with and without the leading dot of domain cookies
+func cookieHosts(host string) []any {
+	host = strings.TrimPrefix(host, ".")
+	return []any{host, "." + host}
+}
@@ -0,0 +1,119 @@
+package browser
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newCookieDB creates a cookie database in write-ahead log mode with the statements.
+// The database stays open until the end of the test, so the rows are only in the -wal file like while the browser runs.
+func newCookieDB(t *testing.T, name string, statements ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	db, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+
+	for _, s := range append([]string{"PRAGMA journal_mode = WAL", "PRAGMA wal_autocheckpoint = 0"}, statements...) {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+	}
+	if info, err := os.Stat(path + "-wal"); err != nil || info.Size() == 0 {
+		t.Fatalf("the rows aren't in the write-ahead log: %v", err)
+	}
+	return path
+}
+
+const firefoxSchema = `CREATE TABLE moz_cookies (name TEXT, value TEXT, host TEXT, expiry INTEGER)`
+
+func TestFirefoxCookie(t *testing.T) {
+	path := newCookieDB(t, "cookies.sqlite", firefoxSchema,
+		`INSERT INTO moz_cookies VALUES ('session', 'old', '.adventofcode.com', 1)`,
+		`INSERT INTO moz_cookies VALUES ('session', 'new', '.adventofcode.com', 2)`,
+		`INSERT INTO moz_cookies VALUES ('session', 'other', '.example.com', 3)`,
+		`INSERT INTO moz_cookies VALUES ('_ga', 'tracking', '.adventofcode.com', 4)`,
+	)
+
+	tests := []struct {
+		name    string
+		profile string
+	}{
+		{"database", path},
+		{"profile folder", filepath.Dir(path)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SessionCookie("firefox", tt.profile, "adventofcode.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != "new" {
+				t.Errorf("cookie = %q, want the one which expires last", got)
+			}
+		})
+	}
+}
+
+func TestFirefoxNoCookie(t *testing.T) {
+	path := newCookieDB(t, "cookies.sqlite", firefoxSchema,
+		`INSERT INTO moz_cookies VALUES ('session', 'other', '.example.com', 1)`,
+	)
+	if _, err := SessionCookie("firefox", path, "adventofcode.com"); !errors.Is(err, ErrNoCookie) {
+		t.Errorf("err = %v, want ErrNoCookie", err)
+	}
+}
+
+func TestFindDatabase(t *testing.T) {
+	dir := t.TempDir()
+	older := filepath.Join(dir, "a", "Cookies")
+	newer := filepath.Join(dir, "b", "Cookies")
+	for _, path := range []string{older, newer} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chtimes(older, time.Unix(0, 0), time.Unix(0, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		profile    string
+		candidates []string
+		want       string
+	}{
+		{"newest candidate", "", []string{older, newer, filepath.Join(dir, "missing")}, newer},
+		{"profile folder", filepath.Join(dir, "a"), []string{newer}, older},
+		{"database", older, nil, older},
+		{"nothing found", "", []string{filepath.Join(dir, "missing")}, ""},
+		{"empty profile folder", dir, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findDatabase(tt.profile, []string{"Cookies"}, tt.candidates)
+			if (err == nil) != (tt.want != "") {
+				t.Fatalf("err = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("database = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnsupportedBrowser(t *testing.T) {
+	if _, err := SessionCookie("lynx", "", "adventofcode.com"); err == nil {
+		t.Error("no error for an unsupported browser")
+	}
+}
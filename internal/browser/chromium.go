@@ -0,0 +1,190 @@
+package browser
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	ss "github.com/zalando/go-keyring/secret_service"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// the parameters of the cookie encryption of Chromium on Linux
+const (
+	chromiumSalt       = "saltysalt"
+	chromiumIterations = 1
+	chromiumKeyLength  = 16
+	// chromiumV10Password is used if no keyring is available
+	chromiumV10Password = "peanuts"
+)
+
+// chromiumIV is the fixed initialization vector of the cookie encryption
+var chromiumIV = bytes.Repeat([]byte{' '}, aes.BlockSize)
+
+// chromiumHashedHostVersion is the database version since which the SHA-256 of the host is prepended to the value
+const chromiumHashedHostVersion = 24
+
+// chromiumApplications are the names under which the browsers store their password in the keyring
+var chromiumApplications = []string{"chromium", "chrome", "brave"}
+
+// chromiumProfiles returns the cookie databases of all profiles of Chromium and Chrome in the default locations
+func chromiumProfiles() []string {
+	config, _ := os.UserConfigDir()
+
+	var patterns []string
+	for _, browser := range []string{"chromium", "google-chrome", filepath.Join("BraveSoftware", "Brave-Browser")} {
+		for _, profile := range []string{"Default", "Profile *"} {
+			dir := filepath.Join(config, browser, profile)
+			patterns = append(patterns, filepath.Join(dir, "Cookies"), filepath.Join(dir, "Network", "Cookies"))
+		}
+	}
+	return globAll(patterns...)
+}
+
+// chromiumCookie reads the session cookie from the Cookies database and decrypts it
+func chromiumCookie(profile string, host string) (string, error) {
+	if runtime.GOOS != "linux" {
+		return "", fmt.Errorf("reading the cookies of Chromium is only supported on Linux")
+	}
+
+	path, err := findDatabase(profile, []string{"Cookies", filepath.Join("Network", "Cookies")}, chromiumProfiles())
+	if err != nil {
+		return "", err
+	}
+
+	db, closeDB, err := openCopy(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer closeDB()
+
+	hosts := cookieHosts(host)
+	var value string
+	var encrypted []byte
+	err = db.QueryRow(`SELECT value, encrypted_value FROM cookies WHERE name = 'session' AND host_key IN (?, ?) ORDER BY expires_utc DESC LIMIT 1`, hosts...).Scan(&value, &encrypted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w in %s", ErrNoCookie, path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read the cookies from %s: %v", path, err)
+	}
+
+	if len(encrypted) == 0 {
+		return value, nil
+	}
+
+	var version int
+	var rawVersion string
+	if err := db.QueryRow(`SELECT value FROM meta WHERE key = 'version'`).Scan(&rawVersion); err == nil {
+		version, _ = strconv.Atoi(rawVersion)
+	}
+
+	plaintext, err := decryptChromium(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt the cookie from %s: %v", path, err)
+	}
+	if version >= chromiumHashedHostVersion {
+		if len(plaintext) < sha256.Size {
+			return "", fmt.Errorf("failed to decrypt the cookie from %s: value too short", path)
+		}
+		// the SHA-256 of the host which binds the value to the cookie
+		plaintext = plaintext[sha256.Size:]
+	}
+
+	return string(plaintext), nil
+}
+
+// decryptChromium decrypts a value encrypted by Chromium on Linux.
+// Values with the prefix v10 use a fixed password, the ones with v11 the password stored in the keyring.
+func decryptChromium(encrypted []byte) ([]byte, error) {
+	if len(encrypted) < 3 {
+		return nil, fmt.Errorf("value too short")
+	}
+
+	var password string
+	switch prefix := string(encrypted[:3]); prefix {
+	case "v10":
+		password = chromiumV10Password
+	case "v11":
+		p, err := chromiumKeyringPassword()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the password from the keyring: %v", err)
+		}
+		password = p
+	default:
+		return nil, fmt.Errorf("unsupported encryption %q", prefix)
+	}
+
+	key := pbkdf2.Key([]byte(password), []byte(chromiumSalt), chromiumIterations, chromiumKeyLength, sha1.New)
+	return decryptAESCBC(key, encrypted[3:])
+}
+
+func decryptAESCBC(key []byte, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("invalid length of the value")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, chromiumIV).CryptBlocks(plaintext, ciphertext)
+
+	// remove the PKCS#7 padding, a wrong key results in an invalid padding
+	n := int(plaintext[len(plaintext)-1])
+	if n == 0 || n > aes.BlockSize || !bytes.Equal(plaintext[len(plaintext)-n:], bytes.Repeat([]byte{byte(n)}, n)) {
+		return nil, fmt.Errorf("wrong key")
+	}
+	return plaintext[:len(plaintext)-n], nil
+}
+
+// chromiumKeyringPassword returns the password of the browser from the Secret Service
+func chromiumKeyringPassword() (string, error) {
+	svc, err := ss.NewSecretService()
+	if err != nil {
+		return "", err
+	}
+
+	collection := svc.GetLoginCollection()
+	if err := svc.Unlock(collection.Path()); err != nil {
+		return "", err
+	}
+
+	for _, application := range chromiumApplications {
+		items, err := svc.SearchItems(collection, map[string]string{"application": application})
+		if err != nil {
+			return "", err
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		session, err := svc.OpenSession()
+		if err != nil {
+			return "", err
+		}
+		defer svc.Close(session)
+
+		if err := svc.Unlock(items[0]); err != nil {
+			return "", err
+		}
+		secret, err := svc.GetSecret(items[0], session.Path())
+		if err != nil {
+			return "", err
+		}
+		return string(secret.Value), nil
+	}
+
+	return "", fmt.Errorf("no password of Chromium found")
+}
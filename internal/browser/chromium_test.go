@@ -0,0 +1,99 @@
+package browser
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"runtime"
+	"testing"
+)
+
+// chromiumV10Key is the key Chromium derives from the fixed v10 password, the values of the tests are encrypted with it
+// instead of the key of decryptChromium, so a wrong key derivation fails the tests
+const chromiumV10Key = "fd621fe5a2b402539dfa147ca9272778"
+
+// encryptV10 encrypts the value like Chromium on Linux without a keyring
+func encryptV10(t *testing.T, value []byte) []byte {
+	t.Helper()
+	key, _ := hex.DecodeString(chromiumV10Key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := aes.BlockSize - len(value)%aes.BlockSize
+	padded := append(bytes.Clone(value), bytes.Repeat([]byte{byte(n)}, n)...)
+	ciphertext := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, bytes.Repeat([]byte{' '}, aes.BlockSize)).CryptBlocks(ciphertext, padded)
+	return append([]byte("v10"), ciphertext...)
+}
+
+func TestDecryptChromium(t *testing.T) {
+	tests := []struct {
+		name      string
+		encrypted []byte
+		want      string
+		ok        bool
+	}{
+		{"v10", encryptV10(t, []byte("53616c7465645f5f")), "53616c7465645f5f", true},
+		{"full block", encryptV10(t, bytes.Repeat([]byte{'a'}, aes.BlockSize)), string(bytes.Repeat([]byte{'a'}, aes.BlockSize)), true},
+		{"unknown prefix", []byte("v20" + string(bytes.Repeat([]byte{0}, aes.BlockSize))), "", false},
+		{"too short", []byte("v1"), "", false},
+		{"no whole block", []byte("v10abc"), "", false},
+		{"wrong key", append([]byte("v10"), bytes.Repeat([]byte{1}, aes.BlockSize)...), "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decryptChromium(tt.encrypted)
+			if (err == nil) != tt.ok {
+				t.Fatalf("err = %v, want ok %v", err, tt.ok)
+			}
+			if string(got) != tt.want {
+				t.Errorf("value = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChromiumCookie(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the cookies of Chromium are only read on Linux")
+	}
+	hostHash := sha256.Sum256([]byte(".adventofcode.com"))
+
+	tests := []struct {
+		name    string
+		version int
+		value   string
+		encrypt []byte
+	}{
+		{"unencrypted", 23, "plain", nil},
+		{"encrypted", 23, "", encryptV10(t, []byte("secret"))},
+		// since version 24 the hash of the host is prepended to the value
+		{"hashed host", 24, "", encryptV10(t, append(hostHash[:], "secret"...))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := newCookieDB(t, "Cookies",
+				`CREATE TABLE meta (key TEXT, value TEXT)`,
+				fmt.Sprintf(`INSERT INTO meta VALUES ('version', '%d')`, tt.version),
+				`CREATE TABLE cookies (name TEXT, value TEXT, encrypted_value BLOB, host_key TEXT, expires_utc INTEGER)`,
+				fmt.Sprintf(`INSERT INTO cookies VALUES ('session', '%s', X'%x', '.adventofcode.com', 1)`, tt.value, tt.encrypt),
+			)
+
+			got, err := SessionCookie("chromium", path, "adventofcode.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := tt.value
+			if tt.encrypt != nil {
+				want = "secret"
+			}
+			if got != want {
+				t.Errorf("cookie = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package browser
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// firefoxProfiles returns the cookie databases of all Firefox profiles in the default locations
+func firefoxProfiles() []string {
+	home, _ := os.UserHomeDir()
+	config, _ := os.UserConfigDir()
+
+	return globAll(
+		filepath.Join(home, ".mozilla", "firefox", "*", "cookies.sqlite"),
+		filepath.Join(home, "snap", "firefox", "common", ".mozilla", "firefox", "*", "cookies.sqlite"),
+		filepath.Join(home, ".var", "app", "org.mozilla.firefox", ".mozilla", "firefox", "*", "cookies.sqlite"),
+		// macOS and Windows
+		filepath.Join(config, "Firefox", "Profiles", "*", "cookies.sqlite"),
+		filepath.Join(config, "Mozilla", "Firefox", "Profiles", "*", "cookies.sqlite"),
+	)
+}
+
+// firefoxCookie reads the session cookie from cookies.sqlite, Firefox stores the values unencrypted
+func firefoxCookie(profile string, host string) (string, error) {
+	path, err := findDatabase(profile, []string{"cookies.sqlite"}, firefoxProfiles())
+	if err != nil {
+		return "", err
+	}
+
+	db, closeDB, err := openCopy(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer closeDB()
+
+	hosts := cookieHosts(host)
+	var value string
+	err = db.QueryRow(`SELECT value FROM moz_cookies WHERE name = 'session' AND host IN (?, ?) ORDER BY expiry DESC LIMIT 1`, hosts...).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w in %s", ErrNoCookie, path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read the cookies from %s: %v", path, err)
+	}
+
+	return value, nil
+}
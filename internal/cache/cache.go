@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cache stores responses of adventofcode.com on disk so they don't have to be requested again
+type Cache struct {
+	dir string
+}
+
+// Key identifies a cached resource of a puzzle for an account
+type Key struct {
+	Account  string
+	Year     int
+	Day      int
+	Resource string
+}
+
+// Entry is a resource stored in the cache
+type Entry struct {
+	Key
+	Size    int64
+	ModTime time.Time
+}
+
+// New returns a cache which stores its entries in the given directory
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// DefaultDir returns the directory where the cache is stored by default
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "aocli"), nil
+}
+
+// Dir returns the directory of the cache
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+func (c *Cache) path(key Key) string {
+	return filepath.Join(c.dir, key.Account, strconv.Itoa(key.Year), fmt.Sprintf("%02d", key.Day), key.Resource)
+}
+
+// Get returns the cached data for the key, when it was stored and if it was found
+func (c *Cache) Get(key Key) ([]byte, time.Time, bool) {
+	f, err := os.Open(c.path(key))
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	return data, info.ModTime(), true
+}
+
+// Put stores the data for the key in the cache
+func (c *Cache) Put(key Key, data []byte) error {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so a concurrent reader never sees a partial entry
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+key.Resource+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Delete removes the entry for the key from the cache
+func (c *Cache) Delete(key Key) error {
+	err := os.Remove(c.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// List returns all entries stored in the cache
+func (c *Cache) List() ([]Entry, error) {
+	var entries []Entry
+
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(c.dir, path)
+		if err != nil {
+			return err
+		}
+		key, ok := parseKey(rel)
+		if !ok {
+			// not an entry of the cache, e.g. an unfinished temporary file
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, Entry{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Remove deletes all entries for which the match function returns true and returns the number of deleted entries
+func (c *Cache) Remove(match func(Entry) bool) (int, error) {
+	entries, err := c.List()
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, e := range entries {
+		if !match(e) {
+			continue
+		}
+		if err := c.Delete(e.Key); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	c.removeEmptyDirs()
+	return n, nil
+}
+
+// removeEmptyDirs cleans up the directories which are left behind after removing entries
+func (c *Cache) removeEmptyDirs() {
+	var dirs []string
+	filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && path != c.dir {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+
+	// remove the deepest directories first, os.Remove fails for directories which are not empty
+	for i := len(dirs) - 1; i >= 0; i-- {
+		os.Remove(dirs[i])
+	}
+}
+
+// parseKey parses a path relative to the cache directory in the form account/year/day/resource
+func parseKey(rel string) (Key, bool) {
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) != 4 {
+		return Key{}, false
+	}
+
+	year, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Key{}, false
+	}
+	day, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return Key{}, false
+	}
+	if parts[3] == "" || parts[3][0] == '.' {
+		return Key{}, false
+	}
+
+	return Key{Account: parts[0], Year: year, Day: day, Resource: parts[3]}, true
+}
@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetPut(t *testing.T) {
+	c := New(t.TempDir())
+	key := Key{Account: "abc", Year: 2024, Day: 1, Resource: "input"}
+
+	if _, _, ok := c.Get(key); ok {
+		t.Fatal("found an entry in the empty cache")
+	}
+
+	before := time.Now().Add(-time.Second)
+	if err := c.Put(key, []byte("1\n2\n")); err != nil {
+		t.Fatal(err)
+	}
+	data, modTime, ok := c.Get(key)
+	if !ok || string(data) != "1\n2\n" {
+		t.Fatalf("Get() = %q, %v", data, ok)
+	}
+	if modTime.Before(before) {
+		t.Errorf("modTime = %v, want the time of Put", modTime)
+	}
+	if path := filepath.Join(c.Dir(), "abc", "2024", "01", "input"); !fileExists(path) {
+		t.Errorf("%s doesn't exist", path)
+	}
+
+	if err := c.Put(key, []byte("3\n")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _, _ := c.Get(key); string(data) != "3\n" {
+		t.Errorf("Get() = %q after overwriting", data)
+	}
+
+	// other accounts have their own entries
+	if _, _, ok := c.Get(Key{Account: "def", Year: 2024, Day: 1, Resource: "input"}); ok {
+		t.Error("found the entry of another account")
+	}
+
+	if err := c.Delete(key); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Delete(key); err != nil {
+		t.Errorf("deleting a missing entry failed: %v", err)
+	}
+	if _, _, ok := c.Get(key); ok {
+		t.Error("found the deleted entry")
+	}
+}
+
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir)
+	keys := []Key{
+		{Account: "abc", Year: 2023, Day: 25, Resource: "page"},
+		{Account: "abc", Year: 2024, Day: 1, Resource: "input"},
+	}
+	for _, key := range keys {
+		if err := c.Put(key, []byte("data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// files which aren't entries are ignored, e.g. an unfinished temporary file
+	for _, name := range []string{"abc/2024/01/.input-123", "abc/2024/notes", "abc/year/01/input"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0o700)
+		if err := os.WriteFile(path, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := c.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(keys) {
+		t.Fatalf("entries = %+v, want %v", entries, keys)
+	}
+	for i, e := range entries {
+		if e.Key != keys[i] || e.Size != 4 {
+			t.Errorf("entry %d = %+v, want %+v with size 4", i, e, keys[i])
+		}
+	}
+
+	if entries, err := New(filepath.Join(dir, "missing")).List(); err != nil || len(entries) != 0 {
+		t.Errorf("List() of a missing cache = %v, %v", entries, err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	c := New(t.TempDir())
+	for day := 1; day <= 3; day++ {
+		for _, resource := range []string{"page", "input"} {
+			if err := c.Put(Key{Account: "abc", Year: 2024, Day: day, Resource: resource}, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	n, err := c.Remove(func(e Entry) bool { return e.Day == 2 || e.Resource == "page" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 {
+		t.Errorf("removed %d entries, want 4", n)
+	}
+
+	entries, _ := c.List()
+	if len(entries) != 2 || entries[0].Day != 1 || entries[1].Day != 3 {
+		t.Errorf("entries = %+v, want the inputs of day 1 and 3", entries)
+	}
+	// the folder of day 2 is empty and removed
+	if fileExists(filepath.Join(c.Dir(), "abc", "2024", "02")) {
+		t.Error("the empty folder wasn't removed")
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -8,13 +9,39 @@ import (
 	"path/filepath"
 
 	"github.com/BurntSushi/toml"
-	"gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Session   string `json:"session" yaml:"session" toml:"session"`
-	Year      int    `json:"year" yaml:"year" toml:"year"`
-	Structure string `json:"structure" yaml:"structure" toml:"structure"`
+	Session     string `json:"session,omitempty" yaml:"session,omitempty" toml:"session,omitempty"`
+	SessionFile string `json:"session_file,omitempty" yaml:"session_file,omitempty" toml:"session_file,omitempty"`
+	Year        int    `json:"year,omitempty" yaml:"year,omitempty" toml:"year,omitempty,omitzero"`
+	Structure   string `json:"structure,omitempty" yaml:"structure,omitempty" toml:"structure,omitempty"`
+	Layout      string `json:"layout,omitempty" yaml:"layout,omitempty" toml:"layout,omitempty"`
+	RateLimit   string `json:"rate_limit,omitempty" yaml:"rate_limit,omitempty" toml:"rate_limit,omitempty"`
+	RateBurst   int    `json:"rate_burst,omitempty" yaml:"rate_burst,omitempty" toml:"rate_burst,omitempty,omitzero"`
+	UserAgent   string `json:"user_agent,omitempty" yaml:"user_agent,omitempty" toml:"user_agent,omitempty"`
+	Contact     string `json:"contact,omitempty" yaml:"contact,omitempty" toml:"contact,omitempty"`
+	Retries     int    `json:"retries,omitempty" yaml:"retries,omitempty" toml:"retries,omitempty,omitzero"`
+	BaseURL     string `json:"base_url,omitempty" yaml:"base_url,omitempty" toml:"base_url,omitempty"`
+
+	// SessionStorage is where "session set" stores the session: keyring or file
+	SessionStorage string `json:"session_storage,omitempty" yaml:"session_storage,omitempty" toml:"session_storage,omitempty"`
+
+	// DefaultTemplate is the name of the template "new" uses if none is given with the flag
+	DefaultTemplate string `json:"default_template,omitempty" yaml:"default_template,omitempty" toml:"default_template,omitempty"`
+
+	// Profile is the name of the profile which is used if none is given with the flag
+	Profile  string             `json:"profile,omitempty" yaml:"profile,omitempty" toml:"profile,omitempty"`
+	Profiles map[string]Profile `json:"profiles,omitempty" yaml:"profiles,omitempty" toml:"profiles,omitempty"`
+}
+
+// Profile holds the settings of an account which replace the top level ones when the profile is used
+type Profile struct {
+	Session     string `json:"session,omitempty" yaml:"session,omitempty" toml:"session,omitempty"`
+	SessionFile string `json:"session_file,omitempty" yaml:"session_file,omitempty" toml:"session_file,omitempty"`
+	Year        int    `json:"year,omitempty" yaml:"year,omitempty" toml:"year,omitempty,omitzero"`
+	Structure   string `json:"structure,omitempty" yaml:"structure,omitempty" toml:"structure,omitempty"`
 }
 
 // Merge merges two Configs, with the values of the second Config taking precedence.
@@ -24,16 +51,90 @@ func Merge(a, b *Config) *Config {
 	if b.Session != "" {
 		a.Session = b.Session
 	}
+	if b.SessionFile != "" {
+		a.SessionFile = b.SessionFile
+	}
 	if b.Year != 0 {
 		a.Year = b.Year
 	}
 	if b.Structure != "" {
 		a.Structure = b.Structure
 	}
+	if b.Layout != "" {
+		a.Layout = b.Layout
+	}
+	if b.SessionStorage != "" {
+		a.SessionStorage = b.SessionStorage
+	}
+	if b.DefaultTemplate != "" {
+		a.DefaultTemplate = b.DefaultTemplate
+	}
+	if b.RateLimit != "" {
+		a.RateLimit = b.RateLimit
+	}
+	if b.RateBurst != 0 {
+		a.RateBurst = b.RateBurst
+	}
+	if b.UserAgent != "" {
+		a.UserAgent = b.UserAgent
+	}
+	if b.Contact != "" {
+		a.Contact = b.Contact
+	}
+	if b.Retries != 0 {
+		a.Retries = b.Retries
+	}
+	if b.BaseURL != "" {
+		a.BaseURL = b.BaseURL
+	}
+	if b.Profile != "" {
+		a.Profile = b.Profile
+	}
+	for name, profile := range b.Profiles {
+		if a.Profiles == nil {
+			a.Profiles = make(map[string]Profile)
+		}
+		a.Profiles[name] = profile
+	}
 
 	return a
 }
 
+// HasPlaintextSession reports if the config or one of its profiles contains a session
+func (c *Config) HasPlaintextSession() bool {
+	if c.Session != "" {
+		return true
+	}
+	for _, p := range c.Profiles {
+		if p.Session != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyProfile replaces the top level settings with the ones set in the profile
+func (c *Config) ApplyProfile(name string) error {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return fmt.Errorf("unknown profile: %s", name)
+	}
+
+	if profile.Session != "" || profile.SessionFile != "" {
+		// the session of the profile must not be mixed with one of the top level
+		c.Session = profile.Session
+		c.SessionFile = profile.SessionFile
+	}
+	if profile.Year != 0 {
+		c.Year = profile.Year
+	}
+	if profile.Structure != "" {
+		c.Structure = profile.Structure
+	}
+
+	return nil
+}
+
 func Parse(path string) (*Config, error) {
 	switch ext := filepath.Ext(path); ext {
 	case ".json":
@@ -110,6 +211,8 @@ func ParseTOML(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+// Write writes the config to the file in the format of its extension.
+// The file is only readable by the user, because it may contain the session.
 func (c *Config) Write(path string) error {
 	switch ext := filepath.Ext(path); ext {
 	case ".json":
@@ -124,13 +227,13 @@ func (c *Config) Write(path string) error {
 }
 
 func (c *Config) WriteJSON(path string) error {
-	f, err := os.Open(path)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	data, err := json.Marshal(c)
+	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -144,18 +247,24 @@ func (c *Config) WriteJSON(path string) error {
 }
 
 func (c *Config) WriteYAML(path string) error {
-	f, err := os.Open(path)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	data, err := yaml.Marshal(c)
-	if err != nil {
+	// the same indentation as the files edited by Update
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
 		return err
 	}
 
-	_, err = f.Write(data)
+	_, err = f.Write(buf.Bytes())
 	if err != nil {
 		return err
 	}
@@ -164,7 +273,7 @@ func (c *Config) WriteYAML(path string) error {
 }
 
 func (c *Config) WriteTOML(path string) error {
-	f, err := os.Open(path)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
 	if err != nil {
 		return err
 	}
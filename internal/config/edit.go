@@ -0,0 +1,575 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// change is the new value of a key, a zero value removes the key
+type change struct {
+	key   string
+	value any
+}
+
+// Update writes the config into the file by only editing the keys which differ from the ones in the file.
+// Comments, the order of the keys and unknown keys are kept, a JSON file gets its keys sorted.
+// If the edited file wouldn't hold exactly the config, nothing is written and an error is returned.
+// A file which doesn't exist yet is written like with Write.
+func (c *Config) Update(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c.Write(path)
+	}
+	if err != nil {
+		return err
+	}
+	ext := filepath.Ext(path)
+	old, err := parseData(ext, data)
+	if err != nil {
+		return err
+	}
+
+	changes := configChanges(old, c)
+	var added, removed []string
+	for name := range c.Profiles {
+		if _, ok := old.Profiles[name]; !ok {
+			added = append(added, name)
+		}
+	}
+	for name := range old.Profiles {
+		if _, ok := c.Profiles[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	if len(changes) == 0 && len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	var edited []byte
+	switch ext {
+	case ".json":
+		edited, err = editJSON(data, changes, added, removed)
+	case ".yaml", ".yml":
+		edited, err = editYAML(data, changes, added, removed)
+	case ".toml":
+		edited, err = editTOML(data, changes, added, removed)
+	default:
+		err = fmt.Errorf("unsupported config file extension: %s", ext)
+	}
+	if err != nil {
+		return err
+	}
+
+	// the edit must not change anything else, otherwise the file is left as it is
+	check, err := parseData(ext, edited)
+	if err != nil || !check.equal(c) {
+		return errors.New("the file can't be edited without losing some of its content, change it by hand")
+	}
+	return writeFileAtomic(path, edited)
+}
+
+// configChanges returns the keys whose values differ in c, in the order of Keys and the profiles sorted by name
+func configChanges(old, c *Config) []change {
+	var changes []change
+	for _, key := range Keys() {
+		before, after := value(reflect.ValueOf(old).Elem(), key), value(reflect.ValueOf(c).Elem(), key)
+		if before != after {
+			changes = append(changes, change{key: key, value: after})
+		}
+	}
+
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		before, after := old.Profiles[name], c.Profiles[name]
+		for _, key := range ProfileKeys() {
+			b, a := value(reflect.ValueOf(&before).Elem(), key), value(reflect.ValueOf(&after).Elem(), key)
+			if b != a {
+				changes = append(changes, change{key: profilesKey + "." + name + "." + key, value: a})
+			}
+		}
+	}
+	return changes
+}
+
+// value returns the string or int of the key in the struct
+func value(v reflect.Value, key string) any {
+	f, _ := field(v, key)
+	return f.Interface()
+}
+
+// equal reports if both configs have the same values and profiles
+func (c *Config) equal(other *Config) bool {
+	if len(configChanges(c, other)) > 0 || len(c.Profiles) != len(other.Profiles) {
+		return false
+	}
+	for name, p := range c.Profiles {
+		if q, ok := other.Profiles[name]; !ok || p != q {
+			return false
+		}
+	}
+	return true
+}
+
+// isZero reports if the value removes the key
+func isZero(value any) bool {
+	return value == "" || value == 0
+}
+
+// parseData parses the content of a config file in the format of the extension
+func parseData(ext string, data []byte) (*Config, error) {
+	var cfg Config
+	var err error
+	switch ext {
+	case ".json":
+		err = json.Unmarshal(data, &cfg)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &cfg)
+	case ".toml":
+		err = toml.Unmarshal(data, &cfg)
+	default:
+		err = fmt.Errorf("unsupported config file extension: %s", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// writeFileAtomic replaces the file with the data, so a failed write doesn't leave half a config behind
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0o600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// editJSON sets the keys in the JSON object, unknown keys are kept but JSON has no order and no comments to keep
+func editJSON(data []byte, changes []change, added, removed []string) ([]byte, error) {
+	var doc map[string]json.RawMessage
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+	}
+	if doc == nil {
+		doc = make(map[string]json.RawMessage)
+	}
+
+	var profiles map[string]map[string]json.RawMessage
+	if raw, ok := doc[profilesKey]; ok {
+		if err := json.Unmarshal(raw, &profiles); err != nil {
+			return nil, err
+		}
+	}
+	if profiles == nil {
+		profiles = make(map[string]map[string]json.RawMessage)
+	}
+
+	set := func(m map[string]json.RawMessage, key string, value any) error {
+		if isZero(value) {
+			delete(m, key)
+			return nil
+		}
+		raw, err := json.Marshal(value)
+		m[key] = raw
+		return err
+	}
+
+	for _, name := range removed {
+		delete(profiles, name)
+	}
+	for _, name := range added {
+		if profiles[name] == nil {
+			profiles[name] = make(map[string]json.RawMessage)
+		}
+	}
+	for _, ch := range changes {
+		if name, key, ok := splitProfileKey(ch.key); ok {
+			if profiles[name] == nil {
+				profiles[name] = make(map[string]json.RawMessage)
+			}
+			if err := set(profiles[name], key, ch.value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := set(doc, ch.key, ch.value); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(profiles) > 0 {
+		raw, err := json.Marshal(profiles)
+		if err != nil {
+			return nil, err
+		}
+		doc[profilesKey] = raw
+	} else {
+		delete(doc, profilesKey)
+	}
+	edited, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(edited, '\n'), nil
+}
+
+// editYAML sets the keys in the nodes of the YAML document, so the comments and the order of the keys are kept
+func editYAML(data []byte, changes []change, added, removed []string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("the document is not a mapping")
+	}
+
+	for _, name := range removed {
+		if profiles := yamlMapping(root, profilesKey, false); profiles != nil {
+			yamlRemove(profiles, name)
+			if len(profiles.Content) == 0 {
+				yamlRemove(root, profilesKey)
+			}
+		}
+	}
+	for _, name := range added {
+		yamlMapping(yamlMapping(root, profilesKey, true), name, true)
+	}
+	for _, ch := range changes {
+		m, key := root, ch.key
+		if name, profileKey, ok := splitProfileKey(ch.key); ok {
+			m = yamlMapping(yamlMapping(root, profilesKey, true), name, true)
+			key = profileKey
+		}
+		if m == nil {
+			return nil, fmt.Errorf("%s is not a mapping", ch.key)
+		}
+
+		if isZero(ch.value) {
+			yamlRemove(m, key)
+			continue
+		}
+		if err := yamlSet(m, key, ch.value); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlMapping returns the mapping of the key, with create it is added if it's missing or empty
+func yamlMapping(m *yaml.Node, key string, create bool) *yaml.Node {
+	if m == nil {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value != key {
+			continue
+		}
+		v := m.Content[i+1]
+		// "work:" without a value is null, it becomes the mapping
+		if create && v.Kind == yaml.ScalarNode && v.Tag == "!!null" {
+			v.Kind, v.Tag, v.Value, v.Style = yaml.MappingNode, "", "", 0
+		}
+		if v.Kind != yaml.MappingNode {
+			return nil
+		}
+		return v
+	}
+	if !create {
+		return nil
+	}
+	v := &yaml.Node{Kind: yaml.MappingNode}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, v)
+	return v
+}
+
+// yamlSet sets the value of the key in the mapping, the comments of a replaced value are kept
+func yamlSet(m *yaml.Node, key string, value any) error {
+	var v yaml.Node
+	if err := v.Encode(value); err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			old := m.Content[i+1]
+			v.HeadComment, v.LineComment, v.FootComment = old.HeadComment, old.LineComment, old.FootComment
+			m.Content[i+1] = &v
+			return nil
+		}
+	}
+	// the profiles stay at the end, after the top level keys
+	i := len(m.Content)
+	for j := 0; j+1 < len(m.Content); j += 2 {
+		if m.Content[j].Value == profilesKey && m.Content[j+1].Kind == yaml.MappingNode {
+			i = j
+		}
+	}
+	m.Content = slices.Insert(m.Content, i, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &v)
+	return nil
+}
+
+// yamlRemove removes the key and its value from the mapping
+func yamlRemove(m *yaml.Node, key string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = slices.Delete(m.Content, i, i+2)
+			return
+		}
+	}
+}
+
+// tomlLine is a line of a TOML file as far as editTOML needs to know it
+type tomlLine struct {
+	// table is the table the line is in or the one of the header
+	table  string
+	header bool
+	// key is the dotted key relative to the table and full the one from the root, both are empty for other lines
+	key  string
+	full string
+}
+
+// scanTOML finds the table headers and keys of the lines, it knows tables and dotted keys but no inline tables
+func scanTOML(lines []string) []tomlLine {
+	scanned := make([]tomlLine, len(lines))
+	table := ""
+	for i, text := range lines {
+		if m := tomlTable.FindStringSubmatch(text); m != nil {
+			table = strings.ReplaceAll(strings.ReplaceAll(m[1], `"`, ""), " ", "")
+			scanned[i] = tomlLine{table: table, header: true}
+			continue
+		}
+		scanned[i].table = table
+		if m := tomlKey.FindStringSubmatch(text); m != nil {
+			scanned[i].key = m[2]
+			scanned[i].full = m[2]
+			if table != "" {
+				scanned[i].full = table + "." + m[2]
+			}
+		}
+	}
+	return scanned
+}
+
+// editTOML edits the lines of the keys in place, new keys are added next to the ones of their table
+func editTOML(data []byte, changes []change, added, removed []string) ([]byte, error) {
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		lines = nil
+	}
+
+	for _, name := range removed {
+		lines = removeTOMLTable(lines, profilesKey+"."+name)
+	}
+
+	for _, ch := range changes {
+		scanned := scanTOML(lines)
+		i := slices.IndexFunc(scanned, func(l tomlLine) bool { return l.full == ch.key })
+		switch {
+		case i >= 0 && isZero(ch.value):
+			lines = slices.Delete(lines, i, i+1)
+		case i >= 0:
+			line, err := replaceTOMLValue(lines[i], ch.value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", ch.key, err)
+			}
+			lines[i] = line
+		case !isZero(ch.value):
+			value, err := formatTOML(ch.value)
+			if err != nil {
+				return nil, err
+			}
+			lines = insertTOMLKey(lines, ch.key, value)
+		}
+	}
+
+	// a new profile without any value still needs its table
+	for _, name := range added {
+		table := profilesKey + "." + name
+		scanned := scanTOML(lines)
+		if !slices.ContainsFunc(scanned, func(l tomlLine) bool {
+			return (l.header && l.table == table) || strings.HasPrefix(l.full, table+".")
+		}) {
+			lines = appendTOMLTable(lines, table)
+		}
+	}
+
+	// a removed table at the end leaves the empty line in front of it
+	return []byte(strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"), nil
+}
+
+// removeTOMLTable removes the table with its keys and sub tables as well as dotted keys into it
+func removeTOMLTable(lines []string, table string) []string {
+	scanned := scanTOML(lines)
+	var kept []string
+	for i, text := range lines {
+		l := scanned[i]
+		inTable := l.table == table || strings.HasPrefix(l.table, table+".")
+		if inTable || l.full == table || strings.HasPrefix(l.full, table+".") {
+			continue
+		}
+		kept = append(kept, text)
+	}
+	return kept
+}
+
+// insertTOMLKey adds the key after the last key of its table, a key of a profile without table gets a new one
+func insertTOMLKey(lines []string, key, value string) []string {
+	scanned := scanTOML(lines)
+	parent := ""
+	if name, _, ok := splitProfileKey(key); ok {
+		parent = profilesKey + "." + name
+	}
+
+	// next to the other keys of the same table, written the same way
+	for i := len(scanned) - 1; i >= 0; i-- {
+		l := scanned[i]
+		if l.full == "" {
+			continue
+		}
+		if parent == "" && l.table == "" {
+			return slices.Insert(lines, i+1, indentOf(lines[i])+key+" = "+value)
+		}
+		if parent != "" && strings.HasPrefix(l.full, parent+".") && !strings.Contains(strings.TrimPrefix(l.full, parent+"."), ".") {
+			name := strings.TrimPrefix(key, l.table+".")
+			if l.table == "" {
+				name = key
+			}
+			return slices.Insert(lines, i+1, indentOf(lines[i])+name+" = "+value)
+		}
+	}
+
+	if parent == "" {
+		// before the first table and the comments above it
+		h := slices.IndexFunc(scanned, func(l tomlLine) bool { return l.header })
+		if h < 0 {
+			return append(lines, key+" = "+value)
+		}
+		for h > 0 && strings.HasPrefix(strings.TrimSpace(lines[h-1]), "#") {
+			h--
+		}
+		return slices.Insert(lines, h, key+" = "+value, "")
+	}
+
+	if h := slices.IndexFunc(scanned, func(l tomlLine) bool { return l.header && l.table == parent }); h >= 0 {
+		return slices.Insert(lines, h+1, strings.TrimPrefix(key, parent+".")+" = "+value)
+	}
+	lines = appendTOMLTable(lines, parent)
+	return append(lines, strings.TrimPrefix(key, parent+".")+" = "+value)
+}
+
+// appendTOMLTable adds the header of the table at the end
+func appendTOMLTable(lines []string, table string) []string {
+	if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+		lines = append(lines, "")
+	}
+	return append(lines, "["+table+"]")
+}
+
+func indentOf(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// replaceTOMLValue replaces the value of the key on the line, a comment behind it is kept
+func replaceTOMLValue(line string, value any) (string, error) {
+	m := tomlKey.FindString(line)
+	rest := line[len(m):]
+	trimmed := strings.TrimLeft(rest, " \t")
+
+	end := 0
+	switch {
+	case strings.HasPrefix(trimmed, `"""`) || strings.HasPrefix(trimmed, `'''`):
+		return "", fmt.Errorf("multi-line strings can't be edited")
+	case strings.HasPrefix(trimmed, `"`):
+		end = -1
+		for i := 1; i < len(trimmed); i++ {
+			if trimmed[i] == '\\' {
+				i++
+			} else if trimmed[i] == '"' {
+				end = i + 1
+				break
+			}
+		}
+	case strings.HasPrefix(trimmed, "'"):
+		end = strings.Index(trimmed[1:], "'")
+		if end >= 0 {
+			end += 2
+		}
+	default:
+		end = strings.Index(trimmed, "#")
+		if end < 0 {
+			end = len(trimmed)
+		}
+		end = len(strings.TrimRight(trimmed[:end], " \t"))
+	}
+	if end < 0 {
+		return "", fmt.Errorf("the string isn't closed")
+	}
+
+	formatted, err := formatTOML(value)
+	if err != nil {
+		return "", err
+	}
+	return m + " " + formatted + trimmed[end:], nil
+}
+
+// formatTOML returns the value as TOML, strings are quoted and escaped
+func formatTOML(value any) (string, error) {
+	if n, ok := value.(int); ok {
+		return strconv.Itoa(n), nil
+	}
+	data, err := toml.Marshal(map[string]any{"v": value})
+	if err != nil {
+		return "", err
+	}
+	_, formatted, _ := strings.Cut(strings.TrimSpace(string(data)), "= ")
+	return formatted, nil
+}
@@ -0,0 +1,341 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// updateTest is an edit of the config and the file expected after Update
+type updateTest struct {
+	name string
+	edit func(c *Config)
+	want string
+}
+
+// edits are the same for every format, only the files differ
+var (
+	editSet           = func(c *Config) { c.Layout = "{{.Year}}/{{.Day}}" }
+	editReplace       = func(c *Config) { c.Year = 2024 }
+	editRemove        = func(c *Config) { c.Structure = "" }
+	editNested        = func(c *Config) { c.Profiles["work"] = Profile{Session: "xyz", Year: 2022} }
+	editAddProfile    = func(c *Config) { c.Profiles["home"] = Profile{Year: 2021} }
+	editRemoveProfile = func(c *Config) { delete(c.Profiles, "work") }
+)
+
+// runUpdateTests writes the input into a config file of the format, edits it and compares the result
+func runUpdateTests(t *testing.T, ext, input string, tests []updateTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config"+ext)
+			if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			c, err := Parse(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.edit(c)
+			if err := c.Update(path); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", data, tt.want)
+			}
+			// the mode of the file is kept
+			if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o644 {
+				t.Errorf("mode = %v, %v, want -rw-r--r--", info.Mode(), err)
+			}
+		})
+	}
+}
+
+func TestUpdateTOML(t *testing.T) {
+	input := `# my config
+year = 2023 # the event
+structure = "single-year"
+my_key = true
+
+# accounts
+[profiles.work]
+# work account
+session = "abc"
+`
+	runUpdateTests(t, ".toml", input, []updateTest{
+		{"set", editSet, `# my config
+year = 2023 # the event
+structure = "single-year"
+my_key = true
+layout = "{{.Year}}/{{.Day}}"
+
+# accounts
+[profiles.work]
+# work account
+session = "abc"
+`},
+		{"replace", editReplace, `# my config
+year = 2024 # the event
+structure = "single-year"
+my_key = true
+
+# accounts
+[profiles.work]
+# work account
+session = "abc"
+`},
+		{"remove", editRemove, `# my config
+year = 2023 # the event
+my_key = true
+
+# accounts
+[profiles.work]
+# work account
+session = "abc"
+`},
+		{"nested", editNested, `# my config
+year = 2023 # the event
+structure = "single-year"
+my_key = true
+
+# accounts
+[profiles.work]
+# work account
+session = "xyz"
+year = 2022
+`},
+		{"add profile", editAddProfile, `# my config
+year = 2023 # the event
+structure = "single-year"
+my_key = true
+
+# accounts
+[profiles.work]
+# work account
+session = "abc"
+
+[profiles.home]
+year = 2021
+`},
+		{"remove profile", editRemoveProfile, `# my config
+year = 2023 # the event
+structure = "single-year"
+my_key = true
+
+# accounts
+`},
+	})
+}
+
+func TestUpdateTOMLDottedKeys(t *testing.T) {
+	input := `year = 2023
+profiles.work.session = "abc" # work account
+`
+	runUpdateTests(t, ".toml", input, []updateTest{
+		{"nested", editNested, `year = 2023
+profiles.work.session = "xyz" # work account
+profiles.work.year = 2022
+`},
+		{"remove profile", editRemoveProfile, `year = 2023
+`},
+	})
+}
+
+func TestUpdateYAML(t *testing.T) {
+	input := `# my config
+year: 2023 # the event
+structure: single-year
+my_key: true
+# accounts
+profiles:
+  work:
+    # work account
+    session: abc
+`
+	runUpdateTests(t, ".yaml", input, []updateTest{
+		{"set", editSet, `# my config
+year: 2023 # the event
+structure: single-year
+my_key: true
+layout: '{{.Year}}/{{.Day}}'
+# accounts
+profiles:
+  work:
+    # work account
+    session: abc
+`},
+		{"replace", editReplace, `# my config
+year: 2024 # the event
+structure: single-year
+my_key: true
+# accounts
+profiles:
+  work:
+    # work account
+    session: abc
+`},
+		{"remove", editRemove, `# my config
+year: 2023 # the event
+my_key: true
+# accounts
+profiles:
+  work:
+    # work account
+    session: abc
+`},
+		{"nested", editNested, `# my config
+year: 2023 # the event
+structure: single-year
+my_key: true
+# accounts
+profiles:
+  work:
+    # work account
+    session: xyz
+    year: 2022
+`},
+		{"add profile", editAddProfile, `# my config
+year: 2023 # the event
+structure: single-year
+my_key: true
+# accounts
+profiles:
+  work:
+    # work account
+    session: abc
+  home:
+    year: 2021
+`},
+		{"remove profile", editRemoveProfile, `# my config
+year: 2023 # the event
+structure: single-year
+my_key: true
+`},
+	})
+}
+
+func TestUpdateJSON(t *testing.T) {
+	input := `{
+  "year": 2023,
+  "structure": "single-year",
+  "my_key": true,
+  "profiles": {
+    "work": {
+      "session": "abc"
+    }
+  }
+}
+`
+	// JSON has no comments, but the unknown key is kept
+	runUpdateTests(t, ".json", input, []updateTest{
+		{"set", editSet, `{
+  "layout": "{{.Year}}/{{.Day}}",
+  "my_key": true,
+  "profiles": {
+    "work": {
+      "session": "abc"
+    }
+  },
+  "structure": "single-year",
+  "year": 2023
+}
+`},
+		{"replace", editReplace, `{
+  "my_key": true,
+  "profiles": {
+    "work": {
+      "session": "abc"
+    }
+  },
+  "structure": "single-year",
+  "year": 2024
+}
+`},
+		{"remove", editRemove, `{
+  "my_key": true,
+  "profiles": {
+    "work": {
+      "session": "abc"
+    }
+  },
+  "year": 2023
+}
+`},
+		{"nested", editNested, `{
+  "my_key": true,
+  "profiles": {
+    "work": {
+      "session": "xyz",
+      "year": 2022
+    }
+  },
+  "structure": "single-year",
+  "year": 2023
+}
+`},
+		{"add profile", editAddProfile, `{
+  "my_key": true,
+  "profiles": {
+    "home": {
+      "year": 2021
+    },
+    "work": {
+      "session": "abc"
+    }
+  },
+  "structure": "single-year",
+  "year": 2023
+}
+`},
+		{"remove profile", editRemoveProfile, `{
+  "my_key": true,
+  "structure": "single-year",
+  "year": 2023
+}
+`},
+	})
+}
+
+func TestUpdateUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	input := "year   =   2023 # odd spacing is kept\n"
+	if err := os.WriteFile(path, []byte(input), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Config{Year: 2023}).Update(path); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != input {
+		t.Errorf("the file changed without a change of the config:\n%s", data)
+	}
+}
+
+func TestUpdateRefusesLossyEdit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	input := "user_agent = \"\"\"\nmy-bot\"\"\"\n"
+	if err := os.WriteFile(path, []byte(input), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Config{UserAgent: "other"}).Update(path); err == nil {
+		t.Error("the multi-line string was edited")
+	}
+	if data, _ := os.ReadFile(path); string(data) != input {
+		t.Errorf("the file changed after the failed edit:\n%s", data)
+	}
+}
+
+func TestUpdateMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := (&Config{Year: 2024, Profiles: map[string]Profile{"work": {Year: 2023}}}).Update(path); err != nil {
+		t.Fatal(err)
+	}
+	want := "year: 2024\nprofiles:\n  work:\n    year: 2023\n"
+	if data, _ := os.ReadFile(path); string(data) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", data, want)
+	}
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// profilesKey is the key of the profiles, the keys of a profile are written as profiles.NAME.KEY
+const profilesKey = "profiles"
+
+// Keys returns the names of all keys of the config, except the ones of the profiles
+func Keys() []string {
+	return fieldKeys(reflect.TypeOf(Config{}))
+}
+
+// ProfileKeys returns the names of the keys a profile can set
+func ProfileKeys() []string {
+	return fieldKeys(reflect.TypeOf(Profile{}))
+}
+
+// fieldKeys returns the keys of the scalar fields of the struct, the names are taken from the toml tags
+func fieldKeys(t reflect.Type) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Type.Kind() != reflect.String && field.Type.Kind() != reflect.Int {
+			continue
+		}
+		keys = append(keys, keyName(field))
+	}
+	return keys
+}
+
+func keyName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+	return name
+}
+
+// field returns the field of the struct with the key
+func field(v reflect.Value, key string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if keyName(t.Field(i)) == key {
+			f := v.Field(i)
+			return f, f.Kind() == reflect.String || f.Kind() == reflect.Int
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// splitProfileKey splits profiles.NAME.KEY into the name of the profile and the key
+func splitProfileKey(key string) (name string, profileKey string, ok bool) {
+	rest, ok := strings.CutPrefix(key, profilesKey+".")
+	if !ok {
+		return "", "", false
+	}
+	i := strings.LastIndex(rest, ".")
+	if i <= 0 {
+		return "", "", false
+	}
+	return rest[:i], rest[i+1:], true
+}
+
+// Get returns the value of the key as string, ok is false if the key isn't set
+func (c *Config) Get(key string) (value string, ok bool, err error) {
+	v := reflect.ValueOf(c).Elem()
+
+	if name, profileKey, isProfile := splitProfileKey(key); isProfile {
+		profile, exists := c.Profiles[name]
+		if !exists {
+			if !slices.Contains(ProfileKeys(), profileKey) {
+				return "", false, fmt.Errorf("unknown key: %s", key)
+			}
+			return "", false, nil
+		}
+		v = reflect.ValueOf(&profile).Elem()
+		key = profileKey
+	}
+
+	f, found := field(v, key)
+	if !found {
+		return "", false, fmt.Errorf("unknown key: %s", key)
+	}
+	if f.IsZero() {
+		return "", false, nil
+	}
+	return fmt.Sprint(f.Interface()), true, nil
+}
+
+// Set parses the value for the type of the key and sets it, an empty value unsets the key
+func (c *Config) Set(key string, value string) error {
+	if name, profileKey, isProfile := splitProfileKey(key); isProfile {
+		profile := c.Profiles[name]
+		if err := setField(reflect.ValueOf(&profile).Elem(), profileKey, value); err != nil {
+			return fmt.Errorf("%v (in profile %s)", err, name)
+		}
+		if c.Profiles == nil {
+			c.Profiles = make(map[string]Profile)
+		}
+		c.Profiles[name] = profile
+		return nil
+	}
+
+	return setField(reflect.ValueOf(c).Elem(), key, value)
+}
+
+func setField(v reflect.Value, key string, value string) error {
+	f, found := field(v, key)
+	if !found {
+		return fmt.Errorf("unknown key: %s", key)
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Int:
+		if value == "" {
+			f.SetInt(0)
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s must be a number: %s", key, value)
+		}
+		f.SetInt(int64(n))
+	}
+	return nil
+}
+
+// SetKeys returns all keys which are set in the config, including the ones of the profiles
+func (c *Config) SetKeys() []string {
+	var keys []string
+	for _, key := range Keys() {
+		if _, ok, _ := c.Get(key); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		for _, profileKey := range ProfileKeys() {
+			key := profilesKey + "." + name + "." + profileKey
+			if _, ok, _ := c.Get(key); ok {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// EnvPrefix is the prefix of the environment variables which override the keys of the config
+const EnvPrefix = "AOCLI_"
+
+// EnvName returns the environment variable of the key, e.g. AOCLI_RATE_LIMIT for rate_limit
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(key)
+}
+
+// FromEnv returns the config set by the environment variables, the profiles can't be set this way
+func FromEnv(lookup func(string) (string, bool)) (*Config, error) {
+	var c Config
+	for _, key := range Keys() {
+		value, ok := lookup(EnvName(key))
+		if !ok || value == "" {
+			continue
+		}
+		if err := c.Set(key, value); err != nil {
+			return nil, fmt.Errorf("invalid environment variable %s: %v", EnvName(key), err)
+		}
+	}
+	return &c, nil
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// envLookup returns a lookup of the environment variables in the map
+func envLookup(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	tests := map[string]string{
+		"year":             "AOCLI_YEAR",
+		"rate_limit":       "AOCLI_RATE_LIMIT",
+		"default_template": "AOCLI_DEFAULT_TEMPLATE",
+	}
+	for key, want := range tests {
+		if got := EnvName(key); got != want {
+			t.Errorf("EnvName(%q) = %s, want %s", key, got, want)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want Config
+	}{
+		{"nothing set", nil, Config{}},
+		{"strings and numbers", map[string]string{
+			"AOCLI_YEAR":       "2023",
+			"AOCLI_STRUCTURE":  "multi-year",
+			"AOCLI_RATE_LIMIT": "500ms",
+			"AOCLI_RATE_BURST": "3",
+			"AOCLI_BASE_URL":   "http://localhost:8080",
+		}, Config{Year: 2023, Structure: "multi-year", RateLimit: "500ms", RateBurst: 3, BaseURL: "http://localhost:8080"}},
+		{"empty values are not set", map[string]string{"AOCLI_YEAR": "", "AOCLI_LAYOUT": ""}, Config{}},
+		{"other variables are ignored", map[string]string{
+			"AOC_YEAR":                     "2023",
+			"aocli_year":                   "2023",
+			"AOCLI_PROFILES":               "work",
+			"AOCLI_PROFILES_WORK_YEAR":     "2023",
+			"AOCLI_DEFAULT_TEMPLATE_OTHER": "go",
+		}, Config{}},
+		{"values are taken as they are", map[string]string{"AOCLI_USER_AGENT": " my bot "}, Config{UserAgent: " my bot "}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := FromEnv(envLookup(tt.env))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !c.equal(&tt.want) {
+				t.Errorf("config = %+v, want %+v", c, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromEnvAllKeys(t *testing.T) {
+	// every key can be set with its variable
+	env := make(map[string]string)
+	for _, key := range Keys() {
+		env[EnvName(key)] = "1"
+	}
+	c, err := FromEnv(envLookup(env))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := c.SetKeys(); !slices.Equal(keys, Keys()) {
+		t.Errorf("set keys = %v, want %v", keys, Keys())
+	}
+}
+
+func TestFromEnvInvalidNumber(t *testing.T) {
+	_, err := FromEnv(envLookup(map[string]string{"AOCLI_RETRIES": "many"}))
+	if err == nil {
+		t.Fatal("the invalid number was accepted")
+	}
+	if !strings.Contains(err.Error(), "AOCLI_RETRIES") {
+		t.Errorf("the variable isn't named: %v", err)
+	}
+}
+
+func TestGetSet(t *testing.T) {
+	var c Config
+	for _, kv := range [][2]string{{"year", "2023"}, {"layout", "{{.Day}}"}, {"profiles.work.year", "2022"}, {"profiles.a.b.session", "abc"}} {
+		if err := c.Set(kv[0], kv[1]); err != nil {
+			t.Fatal(err)
+		}
+		if value, ok, err := c.Get(kv[0]); err != nil || !ok || value != kv[1] {
+			t.Errorf("Get(%q) = %q, %v, %v, want %q", kv[0], value, ok, err, kv[1])
+		}
+	}
+	// the name of a profile may contain dots, the key is after the last one
+	if c.Profiles["a.b"].Session != "abc" {
+		t.Errorf("profiles = %+v", c.Profiles)
+	}
+
+	if err := c.Set("year", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := c.Get("year"); ok {
+		t.Error("the empty value didn't unset the key")
+	}
+
+	for _, key := range []string{"years", "profiles", "profiles.work.layout"} {
+		if err := c.Set(key, "1"); err == nil {
+			t.Errorf("Set(%q) accepted the unknown key", key)
+		}
+		if _, _, err := c.Get(key); err == nil {
+			t.Errorf("Get(%q) accepted the unknown key", key)
+		}
+	}
+	if err := c.Set("year", "next"); err == nil {
+		t.Error("the invalid number was accepted")
+	}
+}
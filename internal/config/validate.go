@@ -0,0 +1,276 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mitsimi/aocli/internal/layout"
+	"gopkg.in/yaml.v3"
+)
+
+// FirstEventYear is the year of the first Advent of Code
+const FirstEventYear = 2015
+
+var (
+	// Structures are the allowed values of structure
+	Structures = []string{"single-year", "multi-year"}
+	// SessionStorages are the allowed values of session_storage
+	SessionStorages = []string{"keyring", "file"}
+)
+
+// eventTimezone is the timezone the puzzles unlock in
+var eventTimezone = time.FixedZone("EST", -5*60*60)
+
+// LatestEventYear returns the year of the current event or, before December, of the last one
+func LatestEventYear() int {
+	now := time.Now().In(eventTimezone)
+	if now.Month() != time.December {
+		return now.Year() - 1
+	}
+	return now.Year()
+}
+
+// Problem is a value of the config which isn't valid
+type Problem struct {
+	Key     string
+	Message string
+	// Line and Column locate the key in the file, they are 0 if it couldn't be found
+	Line   int
+	Column int
+}
+
+func (p Problem) String() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("%d:%d: %s: %s", p.Line, p.Column, p.Key, p.Message)
+	}
+	return p.Key + ": " + p.Message
+}
+
+// ValidationError holds all problems found in a config
+type ValidationError struct {
+	// Path is the file of the config, it is empty if the config doesn't come from a file
+	Path     string
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = p.String()
+		if e.Path != "" {
+			lines[i] = e.Path + ":" + lines[i]
+		}
+	}
+	return "invalid config:\n  " + strings.Join(lines, "\n  ")
+}
+
+// Validate checks the values of the config and its profiles, it returns a *ValidationError with all problems
+func (c *Config) Validate() error {
+	var problems []Problem
+	add := func(key, format string, args ...any) {
+		problems = append(problems, Problem{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	validatePath(add, "session_file", c.SessionFile)
+	validateYear(add, "year", c.Year)
+	validateEnum(add, "structure", c.Structure, Structures)
+	if c.Layout != "" {
+		if _, err := layout.Parse(c.Layout); err != nil {
+			add("layout", "%v", err)
+		}
+	}
+
+	if c.RateLimit != "" {
+		if d, err := time.ParseDuration(c.RateLimit); err != nil {
+			add("rate_limit", "%q is not a duration like 3s or 500ms", c.RateLimit)
+		} else if d < 0 {
+			add("rate_limit", "must not be negative")
+		}
+	}
+	if c.RateBurst < 0 {
+		add("rate_burst", "must not be negative")
+	}
+	if c.BaseURL != "" {
+		if u, err := url.Parse(c.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("base_url", "%q is not an http or https URL", c.BaseURL)
+		}
+	}
+	if strings.ContainsAny(c.UserAgent, "\r\n") {
+		add("user_agent", "must be a single line")
+	}
+	if strings.ContainsAny(c.Contact, "\r\n") {
+		add("contact", "must be a single line")
+	}
+	validateEnum(add, "session_storage", c.SessionStorage, SessionStorages)
+	// the name is a folder in the templates folder
+	if c.DefaultTemplate != "" && (c.DefaultTemplate == "." || c.DefaultTemplate == ".." || strings.ContainsAny(c.DefaultTemplate, "/\\")) {
+		add("default_template", "the name of a template must not contain / or \\")
+	}
+
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		p := c.Profiles[name]
+		prefix := profilesKey + "." + name + "."
+		// the name is used in the paths of the cache and the session file
+		if name == "" || strings.ContainsAny(name, "/\\") {
+			add(profilesKey+"."+name, "the name of a profile must not be empty or contain / or \\")
+		}
+		validatePath(add, prefix+"session_file", p.SessionFile)
+		validateYear(add, prefix+"year", p.Year)
+		validateEnum(add, prefix+"structure", p.Structure, Structures)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: problems}
+}
+
+// RemoveInvalid unsets the values of the problems and removes profiles with an invalid name,
+// so the rest of the config can still be used
+func (c *Config) RemoveInvalid(verr *ValidationError) {
+	for _, p := range verr.Problems {
+		if name, ok := strings.CutPrefix(p.Key, profilesKey+"."); ok {
+			if _, exists := c.Profiles[name]; exists {
+				delete(c.Profiles, name)
+				continue
+			}
+		}
+		c.Set(p.Key, "")
+	}
+}
+
+func validateYear(add func(string, string, ...any), key string, year int) {
+	if year == 0 {
+		return
+	}
+	latest := LatestEventYear()
+	// the short form 23 is 2023
+	full := year
+	if year < 100 {
+		full += 2000
+	}
+	if full < FirstEventYear || full > latest {
+		add(key, "%d is not between %d and %d", year, FirstEventYear, latest)
+	}
+}
+
+func validateEnum(add func(string, string, ...any), key, value string, allowed []string) {
+	if value != "" && !slices.Contains(allowed, value) {
+		add(key, "%q is not one of %s", value, strings.Join(allowed, ", "))
+	}
+}
+
+func validatePath(add func(string, string, ...any), key, path string) {
+	switch {
+	case path == "":
+	case strings.TrimSpace(path) != path:
+		add(key, "must not start or end with whitespace")
+	case strings.ContainsRune(path, 0):
+		add(key, "must not contain a NUL character")
+	case strings.HasPrefix(path, "~") && path != "~" && !strings.HasPrefix(path, "~/"):
+		add(key, "only ~/ is supported for the home folder")
+	}
+}
+
+// ValidateFile validates the config parsed from the file and locates the problems in it
+func ValidateFile(path string, c *Config) error {
+	err := c.Validate()
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		return err
+	}
+	verr.Path = path
+
+	data, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return verr
+	}
+	for i := range verr.Problems {
+		p := &verr.Problems[i]
+		switch filepath.Ext(path) {
+		case ".toml":
+			p.Line, p.Column = locateTOML(data, p.Key)
+		case ".yaml", ".yml":
+			p.Line, p.Column = locateYAML(data, p.Key)
+		}
+	}
+	return verr
+}
+
+var (
+	tomlTable = regexp.MustCompile(`^\s*\[\s*([^\[\]]+?)\s*\]`)
+	tomlKey   = regexp.MustCompile(`^(\s*)"?([A-Za-z0-9_.-]+)"?\s*=`)
+)
+
+// locateTOML returns the position of the dotted key, it knows tables and dotted keys but no inline tables
+func locateTOML(data []byte, key string) (line, column int) {
+	table := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		text := scanner.Text()
+		if m := tomlTable.FindStringSubmatch(text); m != nil {
+			table = strings.ReplaceAll(strings.ReplaceAll(m[1], `"`, ""), " ", "")
+			continue
+		}
+		m := tomlKey.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+		full := m[2]
+		if table != "" {
+			full = table + "." + full
+		}
+		if full == key {
+			return n, len(m[1]) + 1
+		}
+	}
+	return 0, 0
+}
+
+// locateYAML returns the position of the dotted key in the YAML document
+func locateYAML(data []byte, key string) (line, column int) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return 0, 0
+	}
+
+	node := doc.Content[0]
+	parts := []string{key}
+	if name, profileKey, ok := splitProfileKey(key); ok {
+		parts = []string{profilesKey, name, profileKey}
+	}
+	for i, part := range parts {
+		if node.Kind != yaml.MappingNode {
+			return 0, 0
+		}
+		found := false
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == part {
+				if i == len(parts)-1 {
+					return node.Content[j].Line, node.Content[j].Column
+				}
+				node = node.Content[j+1]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, 0
+		}
+	}
+	return 0, 0
+}
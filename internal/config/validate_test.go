@@ -0,0 +1,179 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// problemKeys returns the keys of the problems of the error, it fails if the error isn't a *ValidationError
+func problemKeys(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("err = %v, want a *ValidationError", err)
+	}
+	var keys []string
+	for _, p := range verr.Problems {
+		keys = append(keys, p.Key)
+	}
+	return keys
+}
+
+func TestValidate(t *testing.T) {
+	latest := LatestEventYear()
+	tests := []struct {
+		name string
+		c    Config
+		keys []string
+	}{
+		{"empty", Config{}, nil},
+		{"valid", Config{Year: 2023, Structure: "multi-year", RateLimit: "500ms", SessionStorage: "file", BaseURL: "http://localhost:8080"}, nil},
+		{"first event", Config{Year: FirstEventYear}, nil},
+		{"latest event", Config{Year: latest}, nil},
+		{"short year", Config{Year: 23}, nil},
+		{"year before the first event", Config{Year: 2014}, []string{"year"}},
+		{"year after the latest event", Config{Year: latest + 1}, []string{"year"}},
+		{"unknown structure", Config{Structure: "multi-yr"}, []string{"structure"}},
+		{"unknown session storage", Config{SessionStorage: "vault"}, []string{"session_storage"}},
+		{"invalid layout", Config{Layout: "puzzles"}, []string{"layout"}},
+		{"invalid rate limit", Config{RateLimit: "3"}, []string{"rate_limit"}},
+		{"negative rate limit", Config{RateLimit: "-1s"}, []string{"rate_limit"}},
+		{"negative burst", Config{RateBurst: -1}, []string{"rate_burst"}},
+		{"base url without scheme", Config{BaseURL: "localhost:8080"}, []string{"base_url"}},
+		{"multi-line user agent", Config{UserAgent: "a\nb"}, []string{"user_agent"}},
+		{"template path", Config{DefaultTemplate: "../go"}, []string{"default_template"}},
+		{"session file with ~user", Config{SessionFile: "~santa/session"}, []string{"session_file"}},
+		{"profiles", Config{Profiles: map[string]Profile{
+			"work": {Year: 1999, Structure: "flat"},
+			"a/b":  {},
+		}}, []string{"profiles.a/b", "profiles.work.year", "profiles.work.structure"}},
+		{"all problems", Config{Year: 1999, Structure: "flat"}, []string{"year", "structure"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if keys := problemKeys(t, tt.c.Validate()); !slices.Equal(keys, tt.keys) {
+				t.Errorf("problems = %v, want %v", keys, tt.keys)
+			}
+		})
+	}
+}
+
+func TestValidateEnv(t *testing.T) {
+	env := map[string]string{
+		"AOCLI_YEAR":       "1999",
+		"AOCLI_STRUCTURE":  "multi-year",
+		"AOCLI_RATE_LIMIT": "fast",
+	}
+	c, err := FromEnv(func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := problemKeys(t, c.Validate()); !slices.Equal(keys, []string{"year", "rate_limit"}) {
+		t.Errorf("problems = %v, want [year rate_limit]", keys)
+	}
+}
+
+func TestValidateFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		data  string
+		lines map[string][2]int
+	}{
+		{"toml", "config.toml", `# aoc
+year = 3000
+  structure = "flat"
+
+[profiles.work]
+year = 1999
+`, map[string][2]int{"year": {2, 1}, "structure": {3, 3}, "profiles.work.year": {6, 1}}},
+		{"toml dotted keys", "config.toml", `profiles.work.year = 1999
+`, map[string][2]int{"profiles.work.year": {1, 1}}},
+		{"yaml", "config.yaml", `# aoc
+year: 3000
+structure: flat
+profiles:
+  work:
+    year: 1999
+`, map[string][2]int{"year": {2, 1}, "structure": {3, 1}, "profiles.work.year": {6, 5}}},
+		// JSON has no positions, the problems are still reported
+		{"json", "config.json", `{"year": 3000, "structure": "flat", "profiles": {"work": {"year": 1999}}}`,
+			map[string][2]int{"year": {0, 0}, "structure": {0, 0}, "profiles.work.year": {0, 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			c, err := Parse(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var verr *ValidationError
+			if !errors.As(ValidateFile(path, c), &verr) {
+				t.Fatal("the invalid config passed")
+			}
+			if verr.Path != path {
+				t.Errorf("path = %s, want %s", verr.Path, path)
+			}
+			if len(verr.Problems) != len(tt.lines) {
+				t.Errorf("problems = %v, want %d", verr.Problems, len(tt.lines))
+			}
+			for _, p := range verr.Problems {
+				if want, ok := tt.lines[p.Key]; !ok || p.Line != want[0] || p.Column != want[1] {
+					t.Errorf("%s at %d:%d, want %v", p.Key, p.Line, p.Column, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	err := &ValidationError{Path: "/aoc/.aocli.toml", Problems: []Problem{
+		{Key: "year", Message: "3000 is not between 2015 and 2024", Line: 2, Column: 1},
+		{Key: "contact", Message: "must be a single line"},
+	}}
+	want := "invalid config:\n  /aoc/.aocli.toml:2:1: year: 3000 is not between 2015 and 2024\n  /aoc/.aocli.toml:contact: must be a single line"
+	if err.Error() != want {
+		t.Errorf("message:\n%s\nwant:\n%s", err.Error(), want)
+	}
+}
+
+func TestRemoveInvalid(t *testing.T) {
+	c := &Config{
+		Year:      1999,
+		Structure: "multi-year",
+		RateLimit: "fast",
+		Profiles: map[string]Profile{
+			"work": {Year: 1999, Structure: "single-year"},
+			"a/b":  {Year: 2023},
+		},
+	}
+	var verr *ValidationError
+	if !errors.As(c.Validate(), &verr) {
+		t.Fatal("the invalid config passed")
+	}
+	c.RemoveInvalid(verr)
+
+	if err := c.Validate(); err != nil {
+		t.Errorf("still invalid: %v", err)
+	}
+	want := &Config{
+		Structure: "multi-year",
+		Profiles:  map[string]Profile{"work": {Structure: "single-year"}},
+	}
+	if !c.equal(want) {
+		t.Errorf("config = %+v, want %+v", c, want)
+	}
+}
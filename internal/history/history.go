@@ -0,0 +1,85 @@
+// Package history keeps the answers which were submitted to adventofcode.com
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Entry is a submitted answer and the outcome the site reported for it
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Year    int       `json:"year"`
+	Day     int       `json:"day"`
+	Level   int       `json:"level"`
+	Answer  string    `json:"answer"`
+	Outcome string    `json:"outcome"`
+}
+
+// History stores the entries as JSON lines in a file, so adding one only appends to it
+type History struct {
+	path string
+}
+
+// New returns the history stored in the file at the path
+func New(path string) *History {
+	return &History{path: path}
+}
+
+// Path returns the file of the history
+func (h *History) Path() string {
+	return h.path
+}
+
+// Add appends the entry to the history, the file is only readable by the user
+func (h *History) Add(e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(h.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// List returns the entries in the order they were added, a history which doesn't exist yet is empty
+func (h *History) List() ([]Entry, error) {
+	f, err := os.Open(h.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return entries, fmt.Errorf("%s:%d: %v", h.path, n, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
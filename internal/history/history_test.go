@@ -0,0 +1,64 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAddList(t *testing.T) {
+	h := New(filepath.Join(t.TempDir(), "profiles", "work", "history.jsonl"))
+
+	entries, err := h.List()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("new history = %v, %v, want empty", entries, err)
+	}
+
+	now := time.Date(2024, 12, 1, 5, 0, 12, 0, time.UTC)
+	added := []Entry{
+		{Time: now, Year: 2024, Day: 1, Level: 1, Answer: "14", Outcome: "Incorrect answer"},
+		{Time: now.Add(time.Minute), Year: 2024, Day: 1, Level: 1, Answer: "15", Outcome: "Correct answer"},
+	}
+	for _, e := range added {
+		if err := h.Add(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err = h.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(added) {
+		t.Fatalf("entries = %v, want %v", entries, added)
+	}
+	for i := range added {
+		if !entries[i].Time.Equal(added[i].Time) || entries[i].Answer != added[i].Answer || entries[i].Outcome != added[i].Outcome {
+			t.Errorf("entry %d = %+v, want %+v", i, entries[i], added[i])
+		}
+	}
+
+	// the answers are private like the session
+	if info, err := os.Stat(h.Path()); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, %v, want -rw-------", info.Mode(), err)
+	}
+}
+
+func TestListInvalidLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	data := `{"year":2024,"day":1,"level":1,"answer":"15"}
+
+{"year":
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := New(path).List()
+	if err == nil {
+		t.Fatal("the invalid line was accepted")
+	}
+	if len(entries) != 1 || entries[0].Answer != "15" {
+		t.Errorf("entries before the invalid line = %v", entries)
+	}
+}
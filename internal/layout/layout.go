@@ -0,0 +1,277 @@
+package layout
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+const (
+	// SingleYear is the layout of the single-year structure
+	SingleYear = `day{{printf "%02d" .Day}}`
+	// MultiYear is the layout of the multi-year structure
+	MultiYear = `{{.Year}}/day{{printf "%02d" .Day}}`
+)
+
+// Puzzle holds the values a layout is rendered with, the zero values are unknown when it is parsed from a path
+type Puzzle struct {
+	Year int
+	Day  int
+	Slug string
+}
+
+// Layout is the path of a puzzle in the workspace, given as a template like {{.Year}}/day{{printf "%02d" .Day}}.
+// The same template is turned into a regular expression per path element, so the puzzle can be read from a path again.
+type Layout struct {
+	pattern  string
+	tmpl     *template.Template
+	segments []segment
+	// file is set if the last element ends with an extension, then the layout names the solution file instead of a folder
+	file     bool
+	usesYear bool
+	usesSlug bool
+}
+
+// segment is the regular expression of one path element and the field every group of it captures
+type segment struct {
+	re     *regexp.Regexp
+	fields []string
+}
+
+var printfFormat = regexp.MustCompile(`^%(0?)(\d*)d$`)
+var fileExtension = regexp.MustCompile(`\.[A-Za-z][A-Za-z0-9]*$`)
+
+// Parse parses the pattern of a layout.
+// Only {{.Year}}, {{.Day}}, {{.Slug}} and printf with a %d verb, e.g. {{printf "%02d" .Day}}, are supported,
+// because every action has to be readable from a path again.
+func Parse(pattern string) (*Layout, error) {
+	tmpl, err := template.New("layout").Option("missingkey=error").Parse(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Layout{pattern: pattern, tmpl: tmpl}
+	var expr strings.Builder
+	var fields []string
+	var last string
+	endSegment := func() error {
+		// Day01 was always found as well as day01
+		re, err := regexp.Compile("(?i)^" + expr.String() + "$")
+		if err != nil {
+			return err
+		}
+		l.segments = append(l.segments, segment{re: re, fields: fields})
+		expr.Reset()
+		fields = nil
+		return nil
+	}
+
+	hasDay := false
+	for _, node := range tmpl.Tree.Root.Nodes {
+		switch node := node.(type) {
+		case *parse.TextNode:
+			parts := strings.Split(string(node.Text), "/")
+			for i, part := range parts {
+				if i > 0 {
+					if err := endSegment(); err != nil {
+						return nil, err
+					}
+				}
+				expr.WriteString(regexp.QuoteMeta(part))
+			}
+			last = parts[len(parts)-1]
+		case *parse.ActionNode:
+			field, group, err := actionExpr(node)
+			if err != nil {
+				return nil, err
+			}
+			hasDay = hasDay || field == "Day"
+			l.usesYear = l.usesYear || field == "Year"
+			l.usesSlug = l.usesSlug || field == "Slug"
+			expr.WriteString("(" + group + ")")
+			fields = append(fields, field)
+			last = ""
+		default:
+			return nil, fmt.Errorf("%s is not supported in a layout, only {{.Year}}, {{.Day}}, {{.Slug}} and printf", node)
+		}
+	}
+	if err := endSegment(); err != nil {
+		return nil, err
+	}
+	l.file = fileExtension.MatchString(last)
+
+	if !hasDay {
+		return nil, fmt.Errorf("the layout must contain {{.Day}}")
+	}
+	sample, err := l.render(Puzzle{Year: 2024, Day: 1, Slug: "slug"})
+	if err != nil {
+		return nil, err
+	}
+	if !filepath.IsLocal(sample) || path.Clean(sample) != sample {
+		return nil, fmt.Errorf("the layout must be a clean relative path inside of the workspace")
+	}
+	return l, nil
+}
+
+// actionExpr returns the field of an action like {{.Day}}, {{printf "%02d" .Day}} or {{.Day | printf "%02d"}} and the expression matching its output
+func actionExpr(node *parse.ActionNode) (field string, expr string, err error) {
+	unsupported := fmt.Errorf("%s is not supported in a layout, only {{.Year}}, {{.Day}}, {{.Slug}} and printf", node)
+	if len(node.Pipe.Decl) > 0 {
+		return "", "", unsupported
+	}
+
+	format := ""
+	for _, cmd := range node.Pipe.Cmds {
+		for i, arg := range cmd.Args {
+			switch arg := arg.(type) {
+			case *parse.FieldNode:
+				if len(arg.Ident) != 1 || field != "" {
+					return "", "", unsupported
+				}
+				field = arg.Ident[0]
+			case *parse.IdentifierNode:
+				if arg.Ident != "printf" || i != 0 || len(cmd.Args) < 2 {
+					return "", "", unsupported
+				}
+				s, ok := cmd.Args[1].(*parse.StringNode)
+				if !ok {
+					return "", "", unsupported
+				}
+				format = s.Text
+			case *parse.StringNode:
+				if i != 1 {
+					return "", "", unsupported
+				}
+			default:
+				return "", "", unsupported
+			}
+		}
+	}
+
+	switch field {
+	case "Year", "Day":
+	case "Slug":
+		if format != "" {
+			return "", "", fmt.Errorf("%s: the slug can't be formatted", node)
+		}
+		return field, `[a-z0-9]+(?:-[a-z0-9]+)*`, nil
+	case "":
+		return "", "", unsupported
+	default:
+		return "", "", fmt.Errorf("%s: unknown field %s, only Year, Day and Slug are known", node, field)
+	}
+
+	if format == "" {
+		return field, `\d+`, nil
+	}
+	m := printfFormat.FindStringSubmatch(format)
+	if m == nil {
+		return "", "", fmt.Errorf("%s: only %%d with an optional zero padding like %%02d is supported", node)
+	}
+	if m[1] == "0" && m[2] != "" {
+		return field, `\d{` + m[2] + `,}`, nil
+	}
+	if m[2] != "" {
+		return field, ` *\d+`, nil
+	}
+	return field, `\d+`, nil
+}
+
+// String returns the pattern of the layout
+func (l *Layout) String() string {
+	return l.pattern
+}
+
+// IsFile reports if the layout names the solution file of a puzzle instead of its folder
+func (l *Layout) IsFile() bool {
+	return l.file
+}
+
+// UsesYear reports if the path contains the year, otherwise the year can't be read from it
+func (l *Layout) UsesYear() bool {
+	return l.usesYear
+}
+
+// UsesSlug reports if the path contains the slug of the title, which is only known from the puzzle page
+func (l *Layout) UsesSlug() bool {
+	return l.usesSlug
+}
+
+// Depth returns the number of path elements of the layout
+func (l *Layout) Depth() int {
+	return len(l.segments)
+}
+
+// Path returns the path of the puzzle relative to the workspace
+func (l *Layout) Path(p Puzzle) (string, error) {
+	rendered, err := l.render(p)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsLocal(rendered) {
+		return "", fmt.Errorf("the path %s of the puzzle is outside of the workspace", rendered)
+	}
+	return filepath.FromSlash(rendered), nil
+}
+
+func (l *Layout) render(p Puzzle) (string, error) {
+	var buf bytes.Buffer
+	if err := l.tmpl.Execute(&buf, p); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Match reads the puzzle from a path relative to the workspace.
+// The path may also be a parent of the puzzle, e.g. the year folder, then complete is false and only the values of its elements are set.
+func (l *Layout) Match(rel string) (p Puzzle, complete bool, ok bool) {
+	rel = filepath.ToSlash(filepath.Clean(rel))
+	if rel == "." {
+		return Puzzle{}, false, true
+	}
+
+	elems := strings.Split(rel, "/")
+	if len(elems) > len(l.segments) {
+		return Puzzle{}, false, false
+	}
+
+	found := make(map[string]string)
+	for i, elem := range elems {
+		seg := l.segments[i]
+		m := seg.re.FindStringSubmatch(elem)
+		if m == nil {
+			return Puzzle{}, false, false
+		}
+		for j, field := range seg.fields {
+			value := strings.TrimSpace(m[j+1])
+			if field != "Slug" {
+				// 01 and 1 are the same day
+				n, _ := strconv.Atoi(value)
+				value = strconv.Itoa(n)
+			}
+			// a field which occurs more than once must have the same value everywhere
+			if prev, seen := found[field]; seen && prev != value {
+				return Puzzle{}, false, false
+			}
+			found[field] = value
+		}
+	}
+
+	p.Year, _ = strconv.Atoi(found["Year"])
+	p.Day, _ = strconv.Atoi(found["Day"])
+	p.Slug = found["Slug"]
+	return p, len(elems) == len(l.segments), true
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slug turns the title of a puzzle into a name for paths, e.g. "Historian Hysteria" into historian-hysteria
+func Slug(title string) string {
+	return strings.Trim(nonAlphanumeric.ReplaceAllString(strings.ToLower(title), "-"), "-")
+}
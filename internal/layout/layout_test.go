@@ -0,0 +1,121 @@
+package layout
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseErrors(t *testing.T) {
+	patterns := []string{
+		"puzzles",
+		"{{.Year}}",
+		"day{{.Title}}",
+		"{{.Day.Foo}}",
+		`day{{printf "%s" .Day}}`,
+		`{{printf "%02d" .Slug}}-{{.Day}}`,
+		"{{if .Day}}x{{end}}",
+		"../day{{.Day}}",
+		"/day{{.Day}}",
+		"a//day{{.Day}}",
+		"{{.Day",
+	}
+	for _, pattern := range patterns {
+		if _, err := Parse(pattern); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", pattern)
+		}
+	}
+}
+
+func TestPathMatchRoundTrip(t *testing.T) {
+	tests := []struct {
+		pattern string
+		puzzle  Puzzle
+		path    string
+		file    bool
+	}{
+		{SingleYear, Puzzle{Day: 1}, "day01", false},
+		{SingleYear, Puzzle{Day: 25}, "day25", false},
+		{MultiYear, Puzzle{Year: 2024, Day: 7}, "2024/day07", false},
+		{"{{.Year}}/{{.Day}}", Puzzle{Year: 2015, Day: 3}, "2015/3", false},
+		{`{{.Year}}/day{{printf "%02d" .Day}}-{{.Slug}}`, Puzzle{Year: 2024, Day: 1, Slug: "historian-hysteria"}, "2024/day01-historian-hysteria", false},
+		{`{{.Day | printf "%02d"}}_{{.Year}}`, Puzzle{Year: 2023, Day: 9}, "09_2023", false},
+		{`src/bin/{{printf "%02d" .Day}}.rs`, Puzzle{Day: 4}, "src/bin/04.rs", true},
+		{`{{.Year}}/{{.Day}}/{{.Year}}-{{.Day}}.py`, Puzzle{Year: 2022, Day: 12}, "2022/12/2022-12.py", true},
+	}
+	for _, tt := range tests {
+		l, err := Parse(tt.pattern)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.pattern, err)
+			continue
+		}
+		if l.IsFile() != tt.file {
+			t.Errorf("%s: IsFile = %v, want %v", tt.pattern, l.IsFile(), tt.file)
+		}
+		if want := tt.puzzle.Year != 0; l.UsesYear() != want {
+			t.Errorf("%s: UsesYear = %v, want %v", tt.pattern, l.UsesYear(), want)
+		}
+
+		path, err := l.Path(tt.puzzle)
+		if err != nil {
+			t.Errorf("%s: Path(%+v): %v", tt.pattern, tt.puzzle, err)
+			continue
+		}
+		if path != filepath.FromSlash(tt.path) {
+			t.Errorf("%s: Path(%+v) = %s, want %s", tt.pattern, tt.puzzle, path, tt.path)
+		}
+
+		p, complete, ok := l.Match(path)
+		if !ok || !complete || p != tt.puzzle {
+			t.Errorf("%s: Match(%s) = %+v, %v, %v, want %+v, true, true", tt.pattern, path, p, complete, ok, tt.puzzle)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	multi, _ := Parse(MultiYear)
+	slug, _ := Parse(`day{{printf "%02d" .Day}}-{{.Slug}}`)
+	twice, _ := Parse(`{{.Year}}/{{.Year}}-{{.Day}}`)
+
+	tests := []struct {
+		layout   *Layout
+		path     string
+		puzzle   Puzzle
+		complete bool
+		ok       bool
+	}{
+		// the year folder is a parent of the puzzle
+		{multi, "2024", Puzzle{Year: 2024}, false, true},
+		{multi, ".", Puzzle{}, false, true},
+		{multi, "2024/Day03", Puzzle{Year: 2024, Day: 3}, true, true},
+		// a day with more digits than the padding is still the day
+		{multi, "2024/day100", Puzzle{Year: 2024, Day: 100}, true, true},
+		{multi, "2024/day3", Puzzle{}, false, false},
+		{multi, "2024/day03/src", Puzzle{}, false, false},
+		{multi, "notes", Puzzle{}, false, false},
+		{slug, "day01-historian-hysteria", Puzzle{Day: 1, Slug: "historian-hysteria"}, true, true},
+		{slug, "day01-", Puzzle{}, false, false},
+		{twice, "2024/2024-5", Puzzle{Year: 2024, Day: 5}, true, true},
+		{twice, "2024/2023-5", Puzzle{}, false, false},
+	}
+	for _, tt := range tests {
+		p, complete, ok := tt.layout.Match(filepath.FromSlash(tt.path))
+		if p != tt.puzzle || complete != tt.complete || ok != tt.ok {
+			t.Errorf("%s: Match(%s) = %+v, %v, %v, want %+v, %v, %v", tt.layout, tt.path, p, complete, ok, tt.puzzle, tt.complete, tt.ok)
+		}
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"Historian Hysteria":                "historian-hysteria",
+		"Mull It Over":                      "mull-it-over",
+		"I Was Told There Would Be No Math": "i-was-told-there-would-be-no-math",
+		"Reindeer Maze!":                    "reindeer-maze",
+		"  It's 3D  ":                       "it-s-3d",
+	}
+	for title, want := range tests {
+		if got := Slug(title); got != want {
+			t.Errorf("Slug(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package secret
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/godbus/dbus/v5"
+	"github.com/zalando/go-keyring"
+)
+
+// Service is the name under which the tokens are stored in the keyring
+const Service = "aocli"
+
+// ErrNotFound is returned if no token is stored
+var ErrNotFound = errors.New("no session stored")
+
+// Store keeps the session token encrypted at rest
+type Store interface {
+	// Name describes where the token is stored
+	Name() string
+	Get() (string, error)
+	Set(token string) error
+	Delete() error
+}
+
+// Keyring stores the token in the keyring of the system, which is the Secret Service on Linux
+type Keyring struct {
+	User string
+}
+
+func (k Keyring) Name() string {
+	return "keyring (service " + Service + ", user " + k.User + ")"
+}
+
+func (k Keyring) Get() (string, error) {
+	token, err := keyring.Get(Service, k.User)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrNotFound
+	}
+	return token, err
+}
+
+func (k Keyring) Set(token string) error {
+	return keyring.Set(Service, k.User, token)
+}
+
+func (k Keyring) Delete() error {
+	err := keyring.Delete(Service, k.User)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// secretsService is the D-Bus name of the Secret Service, the keyring on Linux and the BSDs
+const secretsService = "org.freedesktop.secrets"
+
+// mocked is set by MockInit, the keyring in memory is always available
+var mocked bool
+
+// MockInit replaces the keyring of the system with one in memory, for the tests
+func MockInit() {
+	keyring.MockInit()
+	mocked = true
+}
+
+// KeyringAvailable reports if the keyring of the system can be used.
+// On Linux it isn't if no Secret Service is running or can be started, e.g. on servers or in containers.
+// Only the session bus is asked, so no keyring is unlocked or started by the check.
+func KeyringAvailable() bool {
+	switch runtime.GOOS {
+	case "darwin", "windows":
+		return true
+	}
+	if mocked {
+		return true
+	}
+
+	// the connection is shared with the keyring, so it isn't closed
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return false
+	}
+	bus := conn.BusObject()
+
+	var running bool
+	if err := bus.Call("org.freedesktop.DBus.NameHasOwner", 0, secretsService).Store(&running); err == nil && running {
+		return true
+	}
+	var activatable []string
+	if err := bus.Call("org.freedesktop.DBus.ListActivatableNames", 0).Store(&activatable); err != nil {
+		return false
+	}
+	return slices.Contains(activatable, secretsService)
+}
+
+// File stores the token in a file encrypted by age with a passphrase
+type File struct {
+	Path string
+	// Passphrase is only called if the file has to be decrypted or encrypted
+	Passphrase func() (string, error)
+}
+
+func (f File) Name() string {
+	return "encrypted file " + f.Path
+}
+
+// Exists reports if the file is there, without asking for the passphrase
+func (f File) Exists() bool {
+	_, err := os.Stat(f.Path)
+	return err == nil
+}
+
+func (f File) Get() (string, error) {
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	passphrase, err := f.Passphrase()
+	if err != nil {
+		return "", err
+	}
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	r, err := age.Decrypt(armor.NewReader(bytes.NewReader(data)), identity)
+	var noMatch *age.NoIdentityMatchError
+	if errors.As(err, &noMatch) {
+		return "", fmt.Errorf("failed to decrypt %s: wrong passphrase", f.Path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: %v", f.Path, err)
+	}
+	token, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: %v", f.Path, err)
+	}
+
+	return strings.TrimSpace(string(token)), nil
+}
+
+func (f File) Set(token string) error {
+	passphrase, err := f.Passphrase()
+	if err != nil {
+		return err
+	}
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	a := armor.NewWriter(&buf)
+	w, err := age.Encrypt(a, recipient)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, token); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := a.Close(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(f.Path, buf.Bytes(), 0o600)
+}
+
+func (f File) Delete() error {
+	err := os.Remove(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
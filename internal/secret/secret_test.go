@@ -0,0 +1,106 @@
+package secret
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyring(t *testing.T) {
+	MockInit()
+	if !KeyringAvailable() {
+		t.Fatal("the mocked keyring isn't available")
+	}
+
+	work, personal := Keyring{User: "work"}, Keyring{User: "personal"}
+	if _, err := work.Get(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+	if err := work.Set("token"); err != nil {
+		t.Fatal(err)
+	}
+	if token, err := work.Get(); err != nil || token != "token" {
+		t.Errorf("Get() = %q, %v", token, err)
+	}
+	// every user has its own token
+	if _, err := personal.Get(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+
+	if err := work.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if err := work.Delete(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+// passphrase returns the passphrase function of a file and counts how often it is called
+func passphrase(p string, calls *int) func() (string, error) {
+	return func() (string, error) {
+		*calls++
+		return p, nil
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aocli", "session.age")
+	var calls int
+	f := File{Path: path, Passphrase: passphrase("correct horse", &calls)}
+
+	if _, err := f.Get(); !errors.Is(err, ErrNotFound) || f.Exists() {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+	if calls != 0 {
+		t.Error("the passphrase was asked for without a file")
+	}
+
+	if err := f.Set("token\n"); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "token") || !strings.HasPrefix(string(data), "-----BEGIN AGE ENCRYPTED FILE-----") {
+		t.Errorf("the file isn't armored age:\n%s", data)
+	}
+
+	// the token is trimmed
+	if token, err := f.Get(); err != nil || token != "token" {
+		t.Errorf("Get() = %q, %v", token, err)
+	}
+	if calls != 2 {
+		t.Errorf("the passphrase was asked for %d times, want 2", calls)
+	}
+
+	wrong := File{Path: path, Passphrase: passphrase("wrong", &calls)}
+	if _, err := wrong.Get(); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("err = %v, want the wrong passphrase", err)
+	}
+
+	if err := f.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Delete(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestFilePassphraseError(t *testing.T) {
+	f := File{Path: filepath.Join(t.TempDir(), "session.age"), Passphrase: func() (string, error) {
+		return "", errors.New("no terminal")
+	}}
+	if err := f.Set("token"); err == nil || f.Exists() {
+		t.Errorf("err = %v, the file was written without a passphrase", err)
+	}
+}
@@ -0,0 +1,161 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ManifestName is the name of the optional manifest of a template, it isn't copied with the other files
+const ManifestName = "template.toml"
+
+// Manifest declares how a template is applied to a new puzzle
+type Manifest struct {
+	// Render are patterns of files which are rendered like the ones ending with .tmpl, e.g. go.mod or *.go
+	Render []string `toml:"render"`
+	// Skip are patterns of files which aren't copied, e.g. README.md
+	Skip []string `toml:"skip"`
+	// PostCreate are commands run in the folder of the puzzle after it was created, e.g. go mod tidy
+	PostCreate []string `toml:"post_create"`
+	// Append are snippets inserted into existing files of the workspace, e.g. to register the day in a dispatcher
+	Append []Snippet `toml:"append"`
+}
+
+// Snippet is text which is inserted into a file of the workspace
+type Snippet struct {
+	// File is the path of the file relative to the root of the workspace
+	File string `toml:"file"`
+	// Before is a marker, the text is inserted before the first line containing it. Without it the text is added at the end.
+	Before string `toml:"before"`
+	Text   string `toml:"text"`
+}
+
+// LoadManifest reads the manifest of the template, a template without one has an empty manifest
+func LoadManifest(src fs.FS) (*Manifest, error) {
+	var m Manifest
+	data, err := fs.ReadFile(src, ManifestName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := toml.Decode(string(data), &m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", ManifestName, err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("%s: unknown key %s", ManifestName, undecoded[0])
+	}
+	for _, s := range m.Append {
+		if s.File == "" || s.Text == "" {
+			return nil, fmt.Errorf("%s: every append needs a file and a text", ManifestName)
+		}
+	}
+	return &m, nil
+}
+
+// matches reports if one of the patterns matches the path of the file in the template or its name
+func matches(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(name)); ok && !strings.Contains(pattern, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Skips reports if the file of the template isn't copied
+func (m *Manifest) Skips(name string) bool {
+	return name == ManifestName || matches(m.Skip, name)
+}
+
+// Renders reports if the file of the template is rendered
+func (m *Manifest) Renders(name string) bool {
+	return strings.HasSuffix(name, Suffix) || matches(m.Render, name)
+}
+
+// Commands returns the post-create commands rendered with the data
+func (m *Manifest) Commands(data Data) ([]string, error) {
+	commands := make([]string, len(m.PostCreate))
+	for i, command := range m.PostCreate {
+		rendered, err := renderText("post_create", command, data)
+		if err != nil {
+			return nil, err
+		}
+		commands[i] = rendered
+	}
+	return commands, nil
+}
+
+// AppendSnippets inserts the snippets rendered with the data into the files below root and returns the changed files.
+// A snippet which is already in the file isn't inserted again, so the same day can be created twice.
+func (m *Manifest) AppendSnippets(root string, data Data) ([]string, error) {
+	var changed []string
+	for _, s := range m.Append {
+		file, err := renderText("append", s.File, data)
+		if err != nil {
+			return changed, err
+		}
+		if !filepath.IsLocal(file) {
+			return changed, fmt.Errorf("the file %s to append to is outside of the workspace", file)
+		}
+		text, err := renderText("append", s.Text, data)
+		if err != nil {
+			return changed, err
+		}
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+
+		target := filepath.Join(root, filepath.FromSlash(file))
+		content, err := os.ReadFile(target)
+		if err != nil {
+			return changed, err
+		}
+		if strings.Contains(string(content), text) {
+			continue
+		}
+
+		updated, err := insertSnippet(string(content), s.Before, text)
+		if err != nil {
+			return changed, fmt.Errorf("%s: %v", file, err)
+		}
+		info, err := os.Stat(target)
+		if err != nil {
+			return changed, err
+		}
+		if err := os.WriteFile(target, []byte(updated), info.Mode().Perm()); err != nil {
+			return changed, err
+		}
+		changed = append(changed, file)
+	}
+	return changed, nil
+}
+
+// insertSnippet inserts the text before the first line containing the marker or at the end without a marker
+func insertSnippet(content, marker, text string) (string, error) {
+	if marker == "" {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		return content + text, nil
+	}
+
+	i := strings.Index(content, marker)
+	if i < 0 {
+		return "", fmt.Errorf("the marker %q wasn't found", marker)
+	}
+	start := strings.LastIndex(content[:i], "\n") + 1
+	return content[:start] + text + content[start:], nil
+}
@@ -0,0 +1,196 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+var testData = Data{Year: 2024, Day: 1, DayPadded: "01", Title: "Historian Hysteria", Slug: "historian-hysteria", Package: "day01", Input: "input"}
+
+func TestLoadManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     *Manifest
+		ok       bool
+	}{
+		{"no manifest", "", &Manifest{}, true},
+		{"all keys", `render = ["*.go"]
+skip = ["README.md"]
+post_create = ["go mod tidy"]
+
+[[append]]
+file = "main.go"
+before = "// days"
+text = "{{.Day}}"
+`, &Manifest{
+			Render:     []string{"*.go"},
+			Skip:       []string{"README.md"},
+			PostCreate: []string{"go mod tidy"},
+			Append:     []Snippet{{File: "main.go", Before: "// days", Text: "{{.Day}}"}},
+		}, true},
+		{"unknown key", "post-create = [\"go mod tidy\"]\n", nil, false},
+		{"append without file", "[[append]]\ntext = \"x\"\n", nil, false},
+		{"append without text", "[[append]]\nfile = \"main.go\"\n", nil, false},
+		{"invalid toml", "render = [\n", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := fstest.MapFS{}
+			if tt.manifest != "" {
+				src[ManifestName] = &fstest.MapFile{Data: []byte(tt.manifest)}
+			}
+			m, err := LoadManifest(src)
+			if (err == nil) != tt.ok {
+				t.Fatalf("err = %v, want ok %v", err, tt.ok)
+			}
+			if !tt.ok {
+				return
+			}
+			if !slices.Equal(m.Render, tt.want.Render) || !slices.Equal(m.Skip, tt.want.Skip) ||
+				!slices.Equal(m.PostCreate, tt.want.PostCreate) || !slices.Equal(m.Append, tt.want.Append) {
+				t.Errorf("manifest = %+v, want %+v", m, tt.want)
+			}
+		})
+	}
+}
+
+func TestSkipsRenders(t *testing.T) {
+	m := &Manifest{Render: []string{"*.go", "cmd/go.mod"}, Skip: []string{"README.md", "docs"}}
+	tests := []struct {
+		name    string
+		skips   bool
+		renders bool
+	}{
+		{ManifestName, true, false},
+		{"README.md", true, false},
+		{"sub/README.md", true, false},
+		{"docs", true, false},
+		{"main.go", false, true},
+		{"sub/main.go", false, true},
+		{"main.py.tmpl", false, true},
+		{"cmd/go.mod", false, true},
+		{"go.mod", false, false},
+		{"input.txt", false, false},
+	}
+	for _, tt := range tests {
+		if got := m.Skips(tt.name); got != tt.skips {
+			t.Errorf("Skips(%q) = %v, want %v", tt.name, got, tt.skips)
+		}
+		if got := m.Renders(tt.name); got != tt.renders {
+			t.Errorf("Renders(%q) = %v, want %v", tt.name, got, tt.renders)
+		}
+	}
+}
+
+func TestCommands(t *testing.T) {
+	m := &Manifest{PostCreate: []string{"go mod init {{.Package}}", "git add ."}}
+	commands, err := m.Commands(testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"go mod init day01", "git add ."}; !slices.Equal(commands, want) {
+		t.Errorf("commands = %v, want %v", commands, want)
+	}
+
+	m = &Manifest{PostCreate: []string{"echo {{.Unknown}}"}}
+	if _, err := m.Commands(testData); err == nil {
+		t.Error("the unknown variable was accepted")
+	}
+}
+
+func TestInsertSnippet(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		marker  string
+		want    string
+		ok      bool
+	}{
+		{"end", "a\n", "", "a\nx\n", true},
+		{"end without newline", "a", "", "a\nx\n", true},
+		{"empty file", "", "", "x\n", true},
+		{"before the marker", "a\n\t// days\n}\n", "// days", "a\nx\n\t// days\n}\n", true},
+		{"first line", "// days\n", "// days", "x\n// days\n", true},
+		{"first marker", "// days\n// days\n", "// days", "x\n// days\n// days\n", true},
+		{"missing marker", "a\n", "// days", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := insertSnippet(tt.content, tt.marker, "x\n")
+			if (err == nil) != tt.ok || got != tt.want {
+				t.Errorf("insertSnippet = %q, %v, want %q, ok %v", got, err, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestAppendSnippets(t *testing.T) {
+	root := t.TempDir()
+	main := filepath.Join(root, "main.go")
+	if err := os.WriteFile(main, []byte("var days = map[int]func(){\n\t// aocli:days\n}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := &Manifest{Append: []Snippet{
+		{File: "main.go", Before: "// aocli:days", Text: "\t{{.Day}}: {{.Package}}.Solve,"},
+		{File: "{{.Year}}.txt", Text: "{{.Title}}"},
+	}}
+	if err := os.WriteFile(filepath.Join(root, "2024.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := m.AppendSnippets(root, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"main.go", "2024.txt"}; !slices.Equal(changed, want) {
+		t.Errorf("changed = %v, want %v", changed, want)
+	}
+	want := "var days = map[int]func(){\n\t1: day01.Solve,\n\t// aocli:days\n}\n"
+	if data, _ := os.ReadFile(main); string(data) != want {
+		t.Errorf("main.go = %q, want %q", data, want)
+	}
+	if info, _ := os.Stat(main); info.Mode().Perm() != 0o600 {
+		t.Errorf("the mode of main.go changed to %v", info.Mode())
+	}
+
+	// the same day isn't registered twice
+	changed, err = m.AppendSnippets(root, testData)
+	if err != nil || len(changed) != 0 {
+		t.Errorf("second append changed %v, %v", changed, err)
+	}
+	if data, _ := os.ReadFile(main); string(data) != want {
+		t.Errorf("main.go after the second append = %q", data)
+	}
+}
+
+func TestAppendSnippetsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		snippet Snippet
+	}{
+		{"outside of the workspace", Snippet{File: "../main.go", Text: "x"}},
+		{"absolute path", Snippet{File: filepath.Join(t.TempDir(), "main.go"), Text: "x"}},
+		{"missing file", Snippet{File: "missing.go", Text: "x"}},
+		{"missing marker", Snippet{File: "main.go", Before: "// days", Text: "x"}},
+		{"invalid text", Snippet{File: "main.go", Text: "{{.Unknown}}"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			m := &Manifest{Append: []Snippet{tt.snippet}}
+			if _, err := m.AppendSnippets(root, testData); err == nil {
+				t.Error("the snippet was accepted")
+			}
+			if data, _ := os.ReadFile(filepath.Join(root, "main.go")); string(data) != "package main\n" {
+				t.Errorf("main.go was changed: %q", data)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// The sources of the templates, a template of the project shadows a global or built-in one with the same name
+const (
+	SourceProject = "project"
+	SourceGlobal  = "global"
+	SourceBuiltin = "built-in"
+)
+
+// ErrNotFound is returned if no source has a template with the name
+var ErrNotFound = errors.New("template not found")
+
+// Template is a named template of the registry
+type Template struct {
+	Name   string
+	Source string
+	// Path is the folder of the template, it is empty for the built-in ones
+	Path string
+	FS   fs.FS
+}
+
+// Dir is a folder with a sub folder per template, e.g. ~/.config/aocli/templates
+type Dir struct {
+	Source string
+	Path   string
+}
+
+// ValidateName checks that the name can be used as the folder of a template
+func ValidateName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid template name %q, it must not be empty or contain / or \\", name)
+	}
+	return nil
+}
+
+// Find returns the template with the name from the first folder which has it, the built-in starters are looked at last
+func Find(name string, dirs ...Dir) (Template, error) {
+	if err := ValidateName(name); err != nil {
+		return Template{}, err
+	}
+
+	for _, dir := range dirs {
+		path := filepath.Join(dir.Path, name)
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return Template{Name: name, Source: dir.Source, Path: path, FS: os.DirFS(path)}, nil
+		}
+	}
+
+	if slices.Contains(Starters(), name) {
+		return Builtin(name)
+	}
+	return Template{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// Builtin returns the built-in starter template with the name
+func Builtin(name string) (Template, error) {
+	if !slices.Contains(Starters(), name) {
+		return Template{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	src, err := fs.Sub(starters, "starters/"+name)
+	if err != nil {
+		return Template{}, err
+	}
+	return Template{Name: name, Source: SourceBuiltin, FS: src}, nil
+}
+
+// List returns all templates of the folders and the built-in ones, sorted by name.
+// Templates with the same name are in the order they are looked up, so only the first of them is used.
+func List(dirs ...Dir) []Template {
+	var templates []Template
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir.Path)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				path := filepath.Join(dir.Path, entry.Name())
+				templates = append(templates, Template{Name: entry.Name(), Source: dir.Source, Path: path, FS: os.DirFS(path)})
+			}
+		}
+	}
+	for _, name := range Starters() {
+		if t, err := Builtin(name); err == nil {
+			templates = append(templates, t)
+		}
+	}
+
+	// the stable sort keeps the order of the lookup for the same name
+	slices.SortStableFunc(templates, func(a, b Template) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return templates
+}
+
+// Files returns the paths of the files of the template
+func (t Template) Files() ([]string, error) {
+	var files []string
+	err := fs.WalkDir(t.FS, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
@@ -0,0 +1,125 @@
+package template
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// newTemplateDirs creates the project and global folders with the templates of the given names
+func newTemplateDirs(t *testing.T, project, global []string) []Dir {
+	t.Helper()
+	dirs := []Dir{
+		{Source: SourceProject, Path: filepath.Join(t.TempDir(), "templates")},
+		{Source: SourceGlobal, Path: filepath.Join(t.TempDir(), "templates")},
+	}
+	for i, names := range [][]string{project, global} {
+		for _, name := range names {
+			path := filepath.Join(dirs[i].Path, name)
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(path, "main.tmpl"), []byte(dirs[i].Source), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return dirs
+}
+
+func TestValidateName(t *testing.T) {
+	for name, ok := range map[string]bool{"go": true, "go-fast": true, ".hidden": true, "": false, ".": false, "..": false, "a/b": false, `a\b`: false} {
+		if err := ValidateName(name); (err == nil) != ok {
+			t.Errorf("ValidateName(%q) = %v, want ok %v", name, err, ok)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	dirs := newTemplateDirs(t, []string{"mine", "go"}, []string{"mine", "shared"})
+
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"mine", SourceProject},
+		{"shared", SourceGlobal},
+		// a template of the project shadows a built-in one
+		{"go", SourceProject},
+		{"python", SourceBuiltin},
+	}
+	for _, tt := range tests {
+		tmpl, err := Find(tt.name, dirs...)
+		if err != nil {
+			t.Errorf("Find(%q): %v", tt.name, err)
+			continue
+		}
+		if tmpl.Name != tt.name || tmpl.Source != tt.source {
+			t.Errorf("Find(%q) = %s from %s, want %s", tt.name, tmpl.Name, tmpl.Source, tt.source)
+		}
+		// the files of the test templates contain their source
+		if data, err := fs.ReadFile(tmpl.FS, "main.tmpl"); tt.source != SourceBuiltin && string(data) != tt.source {
+			t.Errorf("Find(%q) has the files of %q, %v", tt.name, data, err)
+		}
+	}
+
+	if _, err := Find("missing", dirs...); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Find(missing) = %v, want ErrNotFound", err)
+	}
+	if _, err := Find("../mine", dirs...); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Find(../mine) = %v, want an invalid name", err)
+	}
+	if _, err := Builtin("mine"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Builtin(mine) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestList(t *testing.T) {
+	dirs := newTemplateDirs(t, []string{"mine", "go"}, []string{"mine", "shared"})
+	// a file in the folder is no template and a missing folder is no error
+	if err := os.WriteFile(filepath.Join(dirs[0].Path, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dirs = append(dirs, Dir{Source: SourceGlobal, Path: filepath.Join(t.TempDir(), "missing")})
+
+	var got []string
+	for _, tmpl := range List(dirs...) {
+		got = append(got, tmpl.Name+" "+tmpl.Source)
+	}
+	// the templates with the same name are in the order of the lookup, the first one is used
+	want := []string{
+		"go project", "go built-in",
+		"javascript built-in",
+		"mine project", "mine global",
+		"python built-in", "rust built-in",
+		"shared global",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("templates = %v, want %v", got, want)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	dirs := newTemplateDirs(t, []string{"mine"}, nil)
+	if err := os.MkdirAll(filepath.Join(dirs[0].Path, "mine", "src"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dirs[0].Path, "mine", "src", "lib.rs"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := Find("mine", dirs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := tmpl.Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"main.tmpl", "src/lib.rs"}; !slices.Equal(files, want) {
+		t.Errorf("files = %v, want %v", files, want)
+	}
+}
@@ -0,0 +1,33 @@
+package template
+
+import (
+	"embed"
+	"io/fs"
+	"slices"
+)
+
+// the files of the starters end with .tmpl, so the go tool doesn't build them and new renders them
+//
+//go:embed starters
+var starters embed.FS
+
+// Starters returns the names of the built-in starter templates, which are the supported languages
+func Starters() []string {
+	entries, _ := starters.ReadDir("starters")
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	slices.Sort(names)
+	return names
+}
+
+// WriteStarter writes the files of the built-in starter template into the directory
+func WriteStarter(name, dst string) error {
+	src, err := fs.Sub(starters, "starters/"+name)
+	if err != nil {
+		return err
+	}
+	return WriteFiles(src, dst)
+}
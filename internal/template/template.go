@@ -1,16 +1,183 @@
 package template
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
 )
 
+// Suffix marks the files which are rendered with the data of the puzzle, it is stripped from the name of the copy.
+// Other files are copied as they are, so binary files and templates of other languages aren't touched.
+const Suffix = ".tmpl"
+
+// Data are the variables of the template files, e.g. {{.Title}} or {{.DayPadded}}
+type Data struct {
+	Year int
+	Day  int
+	// DayPadded is the day with two digits, e.g. 01
+	DayPadded string
+	Title     string
+	// Slug is the title in lower case with dashes, e.g. historian-hysteria
+	Slug string
+	// Package is a name for the package or module of the solution, e.g. day01
+	Package string
+	// Input is the name of the input file next to the solution, e.g. input or 01.input
+	Input string
+	// URL is the address of the puzzle
+	URL string
+}
+
 // templateExists checks if there is a template folder
 func FolderExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
 }
 
-func CopyContent(src, dst string) error {
-	srcFS := os.DirFS(src)
-	return os.CopyFS(dst, srcFS)
+// Status is what happened to a file of the template when it was copied
+type Status int
+
+const (
+	// Created is a file which didn't exist before
+	Created Status = iota
+	// Overwritten is an existing file which was replaced
+	Overwritten
+	// Kept is an existing file which was left as it was
+	Kept
+)
+
+// Result is the status of a file written by CopyContent
+type Result struct {
+	Path   string
+	Status Status
+}
+
+// CopyContent copies the files of the template into dst, the files ending with .tmpl or declared in the manifest are rendered with the data.
+// Existing files are kept unless overwrite is set, the results tell what happened to every file.
+func CopyContent(src fs.FS, m *Manifest, dst string, data Data, overwrite bool) ([]Result, error) {
+	var results []Result
+	err := fs.WalkDir(src, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, filepath.FromSlash(path))
+		if d.IsDir() {
+			if path != "." && m.Skips(path) {
+				return fs.SkipDir
+			}
+			return os.MkdirAll(target, 0o777)
+		}
+		if m.Skips(path) {
+			return nil
+		}
+
+		name, err := RenderName(d.Name(), data)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		target = filepath.Join(filepath.Dir(target), name)
+		status, err := CopyFile(src, path, target, data, m.Renders(path), overwrite)
+		if err != nil {
+			return err
+		}
+		results = append(results, Result{Path: target, Status: status})
+		return nil
+	})
+	return results, err
+}
+
+// WriteFiles copies the files of the template into dst without rendering them, e.g. to edit a copy of it
+func WriteFiles(src fs.FS, dst string) error {
+	return fs.WalkDir(src, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, filepath.FromSlash(path))
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+
+		data, err := fs.ReadFile(src, path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0o644)
+	})
+}
+
+// RenderName returns the name of the copy of a template file.
+// The names of the files ending with .tmpl are rendered as well, e.g. {{.Package}}_test.go.tmpl.
+func RenderName(name string, data Data) (string, error) {
+	trimmed, ok := strings.CutSuffix(name, Suffix)
+	if !ok {
+		return name, nil
+	}
+
+	rendered, err := renderText(name, trimmed, data)
+	if err != nil {
+		return "", err
+	}
+	if rendered == "" || strings.ContainsAny(rendered, `/\`) {
+		return "", fmt.Errorf("the name %q isn't a valid file name", rendered)
+	}
+	return rendered, nil
+}
+
+// CopyFile copies the file of the template to dst, with render set it is rendered with the data.
+// An existing file is only replaced if overwrite is set.
+func CopyFile(src fs.FS, name, dst string, data Data, render, overwrite bool) (Status, error) {
+	status := Created
+	if _, err := os.Stat(dst); err == nil {
+		if !overwrite {
+			return Kept, nil
+		}
+		status = Overwritten
+	}
+
+	content, err := fs.ReadFile(src, name)
+	if err != nil {
+		return status, err
+	}
+	info, err := fs.Stat(src, name)
+	if err != nil {
+		return status, err
+	}
+
+	if render {
+		rendered, err := renderText(path.Base(name), string(content), data)
+		if err != nil {
+			return status, err
+		}
+		content = []byte(rendered)
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o666|info.Mode().Perm())
+	if err != nil {
+		return status, err
+	}
+	if _, err := io.Copy(out, bytes.NewReader(content)); err != nil {
+		out.Close()
+		return status, err
+	}
+	return status, out.Close()
+}
+
+func renderText(name, text string, data Data) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
 }
@@ -0,0 +1,177 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+// testTemplate has rendered and copied files, a file whose name is rendered and a skipped one
+var testTemplate = fstest.MapFS{
+	"main.go.tmpl":              {Data: []byte("// {{.Title}}\npackage {{.Package}}\n")},
+	"{{.Package}}_test.go.tmpl": {Data: []byte("package {{.Package}}\n")},
+	"go.mod":                    {Data: []byte("module {{.Package}}\n")},
+	"run.sh":                    {Data: []byte("#!/bin/sh\n"), Mode: 0o755},
+	"README.md":                 {Data: []byte("skipped\n")},
+	"lib/{{.Slug}}.txt":         {Data: []byte("{{.Day}}\n")},
+	ManifestName:                {Data: []byte("render = [\"go.mod\"]\nskip = [\"README.md\"]\n")},
+}
+
+// readTree returns the content of all files below the root by their slash separated path
+func readTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		rel, _ := filepath.Rel(root, path)
+		files[filepath.ToSlash(rel)] = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestCopyContent(t *testing.T) {
+	m, err := LoadManifest(testTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := t.TempDir()
+
+	results, err := CopyContent(testTemplate, m, dst, testData, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"main.go":       "// Historian Hysteria\npackage day01\n",
+		"day01_test.go": "package day01\n",
+		"go.mod":        "module day01\n",
+		"run.sh":        "#!/bin/sh\n",
+		// only the names of .tmpl files are rendered
+		"lib/{{.Slug}}.txt": "{{.Day}}\n",
+	}
+	got := readTree(t, dst)
+	if len(got) != len(want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+	for name, content := range want {
+		if got[name] != content {
+			t.Errorf("%s = %q, want %q", name, got[name], content)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(dst, "run.sh")); err != nil || info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("run.sh isn't executable: %v, %v", info.Mode(), err)
+	}
+	for _, r := range results {
+		if r.Status != Created {
+			t.Errorf("%s: status = %v, want created", r.Path, r.Status)
+		}
+	}
+
+	// the solution is kept unless it is overwritten
+	main := filepath.Join(dst, "main.go")
+	if err := os.WriteFile(main, []byte("solved\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		overwrite bool
+		status    Status
+		main      string
+	}{
+		{false, Kept, "solved\n"},
+		{true, Overwritten, want["main.go"]},
+	}
+	for _, tt := range tests {
+		results, err := CopyContent(testTemplate, m, dst, testData, tt.overwrite)
+		if err != nil {
+			t.Fatal(err)
+		}
+		i := slices.IndexFunc(results, func(r Result) bool { return r.Path == main })
+		if i < 0 || results[i].Status != tt.status {
+			t.Errorf("overwrite %v: results = %v, want %v for main.go", tt.overwrite, results, tt.status)
+		}
+		if data, _ := os.ReadFile(main); string(data) != tt.main {
+			t.Errorf("overwrite %v: main.go = %q, want %q", tt.overwrite, data, tt.main)
+		}
+	}
+}
+
+func TestCopyContentInvalidTemplate(t *testing.T) {
+	for name, src := range map[string]fstest.MapFS{
+		"unknown variable": {"main.go.tmpl": {Data: []byte("{{.Unknown}}")}},
+		"invalid syntax":   {"main.go.tmpl": {Data: []byte("{{.Day")}},
+		"invalid name":     {"{{.Unknown}}.go.tmpl": {Data: []byte("")}},
+	} {
+		if _, err := CopyContent(src, &Manifest{}, t.TempDir(), testData, false); err == nil {
+			t.Errorf("%s: the template was copied", name)
+		}
+	}
+}
+
+func TestRenderName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"main.go", "main.go", true},
+		{"{{.Package}}.go", "{{.Package}}.go", true},
+		{"main.go.tmpl", "main.go", true},
+		{"{{.Package}}_test.go.tmpl", "day01_test.go", true},
+		{"{{.DayPadded}}.py.tmpl", "01.py", true},
+		{"{{.Title}}.tmpl", "Historian Hysteria", true},
+		{"{{.Year}}/{{.Day}}.tmpl", "", false},
+		{"{{if false}}x{{end}}.tmpl", "", false},
+		{"{{.Unknown}}.tmpl", "", false},
+	}
+	for _, tt := range tests {
+		got, err := RenderName(tt.name, testData)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("RenderName(%q) = %q, %v, want %q, ok %v", tt.name, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestWriteFiles(t *testing.T) {
+	dst := t.TempDir()
+	if err := WriteFiles(testTemplate, dst); err != nil {
+		t.Fatal(err)
+	}
+	// the copy is for editing, so nothing is rendered or skipped
+	got := readTree(t, dst)
+	if len(got) != len(testTemplate) {
+		t.Errorf("files = %v, want all of the template", got)
+	}
+	for name, f := range testTemplate {
+		if got[name] != string(f.Data) {
+			t.Errorf("%s = %q, want %q", name, got[name], f.Data)
+		}
+	}
+}
+
+func TestStarters(t *testing.T) {
+	if starters := Starters(); !slices.Equal(starters, []string{"go", "javascript", "python", "rust"}) {
+		t.Errorf("starters = %v", starters)
+	}
+	// every starter has to render with the data of a puzzle
+	for _, name := range Starters() {
+		tmpl, err := Builtin(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := LoadManifest(tmpl.FS)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if _, err := CopyContent(tmpl.FS, m, t.TempDir(), testData, false); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}